@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/s3ndd/sen-go/log"
 )
 
 type ClientInterface interface {
@@ -45,10 +49,33 @@ func NewClient(config *Config) *Client {
 
 func (c *Client) Do(req *http.Request) (resp *http.Response, err error) {
 	ctx := req.Context()
-	resp, err = c.httpClient.Do(req.WithContext(ctx))
+	resp, err = c.httpClient.Do(c.propagateBaggage(req.WithContext(ctx)))
 	return
 }
 
+// propagateBaggage returns the request with the baggage header and the request id header
+// carrying the baggage fields and the request id of its context, unless they are already set.
+// The baggage is only sent to the endpoint host, unless DisableBaggage is set.
+func (c *Client) propagateBaggage(req *http.Request) *http.Request {
+	var baggage map[string]string
+	if !c.Config.DisableBaggage && req.URL.Host == c.endpointHost() {
+		baggage = log.BaggageFromContext(req.Context())
+	}
+	requestID := log.RequestIDFromContext(req.Context())
+	if len(baggage) == 0 && requestID == "" {
+		return req
+	}
+
+	req = req.Clone(req.Context())
+	if len(baggage) > 0 && req.Header.Get(log.BaggageHeaderName) == "" {
+		req.Header.Set(log.BaggageHeaderName, log.FormatBaggage(baggage))
+	}
+	if requestID != "" && req.Header.Get(log.RequestIDHeaderName) == "" {
+		req.Header.Set(log.RequestIDHeaderName, requestID)
+	}
+	return req
+}
+
 func (c *Client) DoWithParse(req *http.Request, value interface{}) (*Response, error) {
 	underlying, err := c.Do(req)
 	parsedResp := Response{
@@ -107,6 +134,19 @@ func (c *Client) URL(uri string) string {
 	return c.AddScheme(fmt.Sprintf("%s/%s", c.Config.EndpointURL, uri))
 }
 
+// endpointHost returns the host of the endpoint URL, which may omit the scheme
+func (c *Client) endpointHost() string {
+	endpoint := c.Config.EndpointURL
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "//" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
 func (c *Client) AddScheme(url string) string {
 	if c.Config.UseSecure {
 		return fmt.Sprintf("https://%s", url)
@@ -1,3 +1,17 @@
 module github.com/s3ndd/sen-go/client
 
 go 1.23.5
+
+require github.com/s3ndd/sen-go/log v0.0.0-20230523111834-86a2e888fa36
+
+require (
+	github.com/pkg/errors v0.8.1 // indirect
+	github.com/s3ndd/sen-go/config v0.0.0-20230522201247-6b1fce2ea6f7 // indirect
+	go.opentelemetry.io/otel v1.16.0 // indirect
+	go.opentelemetry.io/otel/trace v1.16.0 // indirect
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+	go.uber.org/zap v1.24.0 // indirect
+)
+
+replace github.com/s3ndd/sen-go/log => ../log
@@ -7,4 +7,7 @@ type Config struct {
 	UseSecure               bool
 	IgnoreCertificateErrors bool
 	Timeout                 time.Duration
+	// DisableBaggage stops sending the baggage fields of the request context in the baggage header.
+	// The baggage is only sent to the host of EndpointURL.
+	DisableBaggage bool
 }
@@ -0,0 +1,279 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy is what the asynchronous logger does when its buffer is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock blocks the caller until the buffer has room.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropNewest drops the entry being logged.
+	OverflowDropNewest OverflowPolicy = "drop-newest"
+	// OverflowDropDebugFirst evicts the oldest buffered entry of the lowest level below the
+	// level of the entry being logged, or drops the entry being logged if there is none.
+	OverflowDropDebugFirst OverflowPolicy = "drop-debug-first"
+)
+
+// AsyncConfig is the configuration for asynchronous buffered logging.
+type AsyncConfig struct {
+	// Enabled turns asynchronous logging on.
+	Enabled bool
+	// BufferSize is the number of entries buffered before the overflow policy applies.
+	BufferSize int
+	// FlushInterval is the maximum time entries stay in the buffer.
+	FlushInterval time.Duration
+	// Overflow is the policy applied when the buffer is full, block by default.
+	Overflow OverflowPolicy
+}
+
+// asyncEntry is an encoded entry waiting to be written.
+type asyncEntry struct {
+	level zapcore.Level
+	buf   *buffer.Buffer
+}
+
+// asyncWriter writes encoded entries to the output from a background goroutine.
+type asyncWriter struct {
+	out      zapcore.WriteSyncer
+	policy   OverflowPolicy
+	interval time.Duration
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	ring    []asyncEntry
+	head    int
+	count   int
+	closed  bool
+
+	writeMu sync.Mutex
+	batch   []byte
+
+	wake chan struct{}
+	done chan struct{}
+	stop sync.Once
+}
+
+// newAsyncWriter creates a new asyncWriter and starts its background goroutine.
+func newAsyncWriter(out zapcore.WriteSyncer, config AsyncConfig) (*asyncWriter, error) {
+	policy := config.Overflow
+	if policy == "" {
+		policy = OverflowBlock
+	}
+	if policy != OverflowBlock && policy != OverflowDropNewest && policy != OverflowDropDebugFirst {
+		return nil, fmt.Errorf("invalid log overflow policy %q", policy)
+	}
+
+	size := config.BufferSize
+	if size <= 0 {
+		size = 4096
+	}
+	interval := config.FlushInterval
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+
+	w := &asyncWriter{
+		out:      out,
+		policy:   policy,
+		interval: interval,
+		ring:     make([]asyncEntry, size),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	w.notFull = sync.NewCond(&w.mu)
+
+	go w.run()
+	return w, nil
+}
+
+// enqueue buffers an encoded entry, applying the overflow policy if the buffer is full.
+func (w *asyncWriter) enqueue(level zapcore.Level, buf *buffer.Buffer) error {
+	w.mu.Lock()
+	for !w.closed && w.count == len(w.ring) {
+		if w.policy == OverflowBlock {
+			w.notFull.Wait()
+			continue
+		}
+		if w.policy == OverflowDropDebugFirst && w.evictBelow(level) {
+			break
+		}
+		w.mu.Unlock()
+		buf.Free()
+		dropped.overflow.Add(1)
+		return nil
+	}
+
+	if w.closed {
+		w.mu.Unlock()
+		return w.writeNow(buf)
+	}
+
+	w.ring[(w.head+w.count)%len(w.ring)] = asyncEntry{level, buf}
+	w.count++
+	halfFull := w.count >= len(w.ring)/2
+	w.mu.Unlock()
+
+	if halfFull {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// evictBelow removes the oldest buffered entry of the lowest level if it is below the level.
+// The caller must hold the lock.
+func (w *asyncWriter) evictBelow(level zapcore.Level) bool {
+	victim := -1
+	for i := 0; i < w.count; i++ {
+		entry := w.ring[(w.head+i)%len(w.ring)]
+		if entry.level < level && (victim < 0 || entry.level < w.ring[(w.head+victim)%len(w.ring)].level) {
+			victim = i
+		}
+	}
+	if victim < 0 {
+		return false
+	}
+
+	w.ring[(w.head+victim)%len(w.ring)].buf.Free()
+	for i := victim; i < w.count-1; i++ {
+		w.ring[(w.head+i)%len(w.ring)] = w.ring[(w.head+i+1)%len(w.ring)]
+	}
+	w.count--
+	w.ring[(w.head+w.count)%len(w.ring)] = asyncEntry{}
+	dropped.overflow.Add(1)
+	return true
+}
+
+// run drains the buffer every flush interval, or sooner when it is half full.
+func (w *asyncWriter) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.wake:
+		case <-w.done:
+			_ = w.drain()
+			return
+		}
+		if err := w.drain(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write the buffered log entries, %s\n", err)
+		}
+	}
+}
+
+// drain writes the buffered entries to the output in a single write.
+func (w *asyncWriter) drain() error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
+	w.mu.Lock()
+	w.batch = w.batch[:0]
+	for i := 0; i < w.count; i++ {
+		index := (w.head + i) % len(w.ring)
+		w.batch = append(w.batch, w.ring[index].buf.Bytes()...)
+		w.ring[index].buf.Free()
+		w.ring[index] = asyncEntry{}
+	}
+	w.head = (w.head + w.count) % len(w.ring)
+	w.count = 0
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+
+	if len(w.batch) == 0 {
+		return nil
+	}
+	_, err := w.out.Write(w.batch)
+	return err
+}
+
+// writeNow writes an encoded entry to the output after the buffered entries.
+func (w *asyncWriter) writeNow(buf *buffer.Buffer) error {
+	err := w.drain()
+
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
+	_, writeErr := w.out.Write(buf.Bytes())
+	buf.Free()
+	return errors.Join(err, writeErr)
+}
+
+// Sync writes the buffered entries and syncs the output.
+func (w *asyncWriter) Sync() error {
+	return errors.Join(w.drain(), w.out.Sync())
+}
+
+// Close writes the buffered entries and stops the background goroutine.
+// Entries logged afterwards are written synchronously.
+func (w *asyncWriter) Close() error {
+	w.stop.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.notFull.Broadcast()
+		w.mu.Unlock()
+		close(w.done)
+	})
+	return w.Sync()
+}
+
+// asyncCore is a zapcore.Core encoding entries in the caller and writing them asynchronously.
+type asyncCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	writer  *asyncWriter
+}
+
+// newAsyncCore creates a new asyncCore.
+func newAsyncCore(encoder zapcore.Encoder, writer *asyncWriter, level zapcore.LevelEnabler) zapcore.Core {
+	return &asyncCore{LevelEnabler: level, encoder: encoder, writer: writer}
+}
+
+// With returns a core encoding the fields with every entry.
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	encoder := c.encoder.Clone()
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	return &asyncCore{LevelEnabler: c.LevelEnabler, encoder: encoder, writer: c.writer}
+}
+
+// Check adds the core to the checked entry if the level is enabled.
+func (c *asyncCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write encodes the entry and buffers it. Entries at DPanic level and above are written
+// synchronously, as the process may exit right after.
+func (c *asyncCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+
+	if entry.Level >= zapcore.DPanicLevel {
+		return errors.Join(c.writer.writeNow(buf), c.writer.out.Sync())
+	}
+	return c.writer.enqueue(entry.Level, buf)
+}
+
+// Sync writes the buffered entries and syncs the output.
+func (c *asyncCore) Sync() error {
+	return c.writer.Sync()
+}
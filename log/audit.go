@@ -0,0 +1,383 @@
+package log
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// AuditOutcome is the outcome of an audited action.
+type AuditOutcome string
+
+const (
+	// AuditSuccess is the outcome of an action that succeeded.
+	AuditSuccess AuditOutcome = "success"
+	// AuditFailure is the outcome of an action that failed.
+	AuditFailure AuditOutcome = "failure"
+	// AuditDenied is the outcome of an action that was not authorized.
+	AuditDenied AuditOutcome = "denied"
+)
+
+// AuditChainStarted is the action of the first record of a chain written to stdout, marking
+// each restart of the chain.
+const AuditChainStarted = "audit.chain_started"
+
+// AuditConfig is the configuration for the audit logger.
+type AuditConfig struct {
+	// File is the file the audit records are written to, with its rotation. Rotated files are
+	// kept as evidence, so MaxBackups and MaxAge are rejected.
+	File FileConfig
+	// Stdout writes the records to stdout, along with the application logs, when the file path
+	// is empty. Either a file path or Stdout is required.
+	Stdout bool
+	// StatePath is the file the last record written to stdout is saved to, so the chain continues
+	// from it after a restart. Without it, every start begins a new chain.
+	StatePath string
+	// Key is the HMAC key of the record hashes. The hashes are plain SHA-256 when empty.
+	Key string
+	// SyncEveryRecord syncs the output after each record.
+	SyncEveryRecord bool
+}
+
+// AuditEvent is an audited action: who did what to which resource, and the outcome.
+type AuditEvent struct {
+	Actor     string
+	Action    string
+	Resource  string
+	Outcome   AuditOutcome
+	RequestID string
+	Details   Fields
+}
+
+// AuditRecord is an audit record as written, chained to the previous record by its hash.
+type AuditRecord struct {
+	Seq       uint64          `json:"seq"`
+	Time      string          `json:"time"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Resource  string          `json:"resource"`
+	Outcome   AuditOutcome    `json:"outcome"`
+	RequestID string          `json:"request_id,omitempty"`
+	TraceID   string          `json:"trace_id,omitempty"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash,omitempty"`
+}
+
+// sharedAudit is the global audit logger
+var sharedAudit atomic.Pointer[AuditLogger]
+
+// sharedAuditMu serializes the creation and replacement of the global audit logger, so a single
+// one continues the chain
+var sharedAuditMu sync.Mutex
+
+// AuditLogger writes hash-chained audit records to its own output. Records are written
+// synchronously and are never sampled, rate limited or dropped.
+type AuditLogger struct {
+	config AuditConfig
+	out    zapcore.WriteSyncer
+	err    error
+
+	mu       sync.Mutex
+	seq      uint64
+	prevHash string
+}
+
+// NewAuditLogger creates a new AuditLogger. The chain continues from the last record of the
+// file, or of the state file when writing to stdout, if it exists. A new chain written to stdout
+// starts with an AuditChainStarted record.
+func NewAuditLogger(config AuditConfig) (*AuditLogger, error) {
+	if config.File.MaxBackups > 0 || config.File.MaxAge > 0 {
+		return nil, errors.New("Failed to create the audit logger. Audit files cannot be deleted, MaxBackups and MaxAge are not supported")
+	}
+
+	logger := &AuditLogger{config: config}
+	if !config.File.Enabled() {
+		if !config.Stdout {
+			return nil, errors.New("Failed to create the audit logger. A file path or stdout is required")
+		}
+		logger.out = stdoutSink{zapcore.Lock(os.Stdout)}
+		if err := logger.continueChain(config.StatePath); err != nil {
+			return nil, err
+		}
+		if logger.seq == 0 {
+			err := logger.Record(context.Background(), AuditEvent{Actor: "system", Action: AuditChainStarted, Outcome: AuditSuccess})
+			if err != nil {
+				return nil, fmt.Errorf("Failed to start the audit chain. %s", err)
+			}
+		}
+		return logger, nil
+	}
+
+	if err := logger.continueChain(config.File.Path); err != nil {
+		return nil, err
+	}
+
+	sink, err := NewFileSink(config.File)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the audit log file sink. %s", err)
+	}
+	logger.out = sink
+	return logger, nil
+}
+
+// Audit returns the global audit logger, created from the environment on first use. If it cannot
+// be created, the logger returned fails each record with the error and creation is retried on the
+// next call.
+func Audit() *AuditLogger {
+	if logger := sharedAudit.Load(); logger != nil {
+		return logger
+	}
+
+	sharedAuditMu.Lock()
+	defer sharedAuditMu.Unlock()
+
+	if logger := sharedAudit.Load(); logger != nil {
+		return logger
+	}
+	logger, err := NewAuditLogger(*defaultAuditConfig())
+	if err != nil {
+		return &AuditLogger{err: err}
+	}
+	sharedAudit.Store(logger)
+	return logger
+}
+
+// InitAudit creates the global audit logger from the config, replacing the current one and
+// closing its output. The config is read from the environment when nil.
+func InitAudit(config *AuditConfig) error {
+	if config == nil {
+		config = defaultAuditConfig()
+	}
+
+	sharedAuditMu.Lock()
+	defer sharedAuditMu.Unlock()
+
+	logger, err := NewAuditLogger(*config)
+	if err != nil {
+		return err
+	}
+	if previous := sharedAudit.Swap(logger); previous != nil {
+		return previous.Close()
+	}
+	return nil
+}
+
+// RecordAudit records the event with the global audit logger.
+func RecordAudit(ctx context.Context, event AuditEvent) error {
+	return Audit().Record(ctx, event)
+}
+
+// Record writes the event as the next record of the chain. The request id and trace id are
+// taken from the context when not set. An error is returned if the record could not be written,
+// so the caller can fail the audited action.
+func (a *AuditLogger) Record(ctx context.Context, event AuditEvent) error {
+	if a.err != nil {
+		return a.err
+	}
+	if event.Actor == "" || event.Action == "" || event.Outcome == "" {
+		return errors.New("audit events require an actor, an action and an outcome")
+	}
+
+	record := AuditRecord{
+		Time:      time.Now().UTC().Format(time.RFC3339Nano),
+		Actor:     event.Actor,
+		Action:    event.Action,
+		Resource:  event.Resource,
+		Outcome:   event.Outcome,
+		RequestID: event.RequestID,
+	}
+	if record.RequestID == "" {
+		record.RequestID = RequestIDFromContext(ctx)
+	}
+	record.TraceID, _, _ = TraceContext(ctx)
+	if len(event.Details) > 0 {
+		details, err := json.Marshal(event.Details)
+		if err != nil {
+			return fmt.Errorf("failed to encode the audit event details, %w", err)
+		}
+		record.Details = details
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	record.Seq = a.seq + 1
+	record.PrevHash = a.prevHash
+	line, err := sealAuditRecord(&record, newAuditHash(a.config.Key))
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.out.Write(line); err != nil {
+		return fmt.Errorf("failed to write the audit record, %w", err)
+	}
+	if a.config.SyncEveryRecord {
+		if err := a.out.Sync(); err != nil {
+			return fmt.Errorf("failed to sync the audit record, %w", err)
+		}
+	}
+
+	if a.config.StatePath != "" && !a.config.File.Enabled() {
+		if err := saveAuditState(a.config.StatePath, line); err != nil {
+			return err
+		}
+	}
+
+	a.seq = record.Seq
+	a.prevHash = record.Hash
+	return nil
+}
+
+// continueChain continues the chain from the last record of the file, if any.
+func (a *AuditLogger) continueChain(path string) error {
+	if path == "" {
+		return nil
+	}
+	last, err := lastAuditRecord(path)
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		a.seq = last.Seq
+		a.prevHash = last.Hash
+	}
+	return nil
+}
+
+// saveAuditState replaces the state file with the record, atomically so a crash cannot truncate it.
+func saveAuditState(path string, line []byte) error {
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, line, 0o600); err != nil {
+		return fmt.Errorf("failed to save the audit state, %w", err)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		return fmt.Errorf("failed to save the audit state, %w", err)
+	}
+	return nil
+}
+
+// Sync syncs the output.
+func (a *AuditLogger) Sync() error {
+	if a.err != nil {
+		return a.err
+	}
+	return a.out.Sync()
+}
+
+// Close syncs and closes the output.
+func (a *AuditLogger) Close() error {
+	if a.err != nil {
+		return a.err
+	}
+	err := a.out.Sync()
+	if closer, ok := a.out.(io.Closer); ok {
+		err = errors.Join(err, closer.Close())
+	}
+	return err
+}
+
+// VerifyAuditLog verifies the hash chain of the audit records read from the reader, returning
+// an error locating the first record that was modified, removed or inserted. The first record
+// may continue the chain of a rotated file, and an AuditChainStarted record starts a new chain.
+func VerifyAuditLog(reader io.Reader, key string) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*megabyte)
+
+	var previous *AuditRecord
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		record := AuditRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("audit record on line %d is invalid, %w", line, err)
+		}
+		chainStarted := record.Action == AuditChainStarted && record.Seq == 1 && record.PrevHash == ""
+		if previous != nil && !chainStarted && (record.Seq != previous.Seq+1 || record.PrevHash != previous.Hash) {
+			return fmt.Errorf("audit record %d on line %d does not follow record %d", record.Seq, line, previous.Seq)
+		}
+
+		expected := record
+		if _, err := sealAuditRecord(&expected, newAuditHash(key)); err != nil {
+			return err
+		}
+		if expected.Hash != record.Hash {
+			return fmt.Errorf("audit record %d on line %d was modified", record.Seq, line)
+		}
+		previous = &record
+	}
+	return scanner.Err()
+}
+
+// sealAuditRecord sets the hash of the record and returns its encoded line.
+func sealAuditRecord(record *AuditRecord, recordHash hash.Hash) ([]byte, error) {
+	record.Hash = ""
+	unsealed, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the audit record, %w", err)
+	}
+	recordHash.Write(unsealed)
+	record.Hash = hex.EncodeToString(recordHash.Sum(nil))
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the audit record, %w", err)
+	}
+	return append(line, '\n'), nil
+}
+
+// newAuditHash returns the hash of the records, an HMAC if there is a key.
+func newAuditHash(key string) hash.Hash {
+	if key == "" {
+		return sha256.New()
+	}
+	return hmac.New(sha256.New, []byte(key))
+}
+
+// lastAuditRecord returns the last record of the file, or nil if the file does not exist or is empty.
+func lastAuditRecord(path string) (*AuditRecord, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the audit log, %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*megabyte)
+	var last []byte
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > 0 {
+			last = append(last[:0], scanner.Bytes()...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the audit log, %w", err)
+	}
+	if last == nil {
+		return nil, nil
+	}
+
+	record := &AuditRecord{}
+	if err := json.Unmarshal(last, record); err != nil {
+		return nil, fmt.Errorf("failed to decode the last audit record, %w", err)
+	}
+	return record, nil
+}
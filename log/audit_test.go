@@ -0,0 +1,159 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeAuditRecords records the actions with a new audit logger and closes it.
+func writeAuditRecords(t *testing.T, config AuditConfig, actions ...string) {
+	logger, err := NewAuditLogger(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range actions {
+		event := AuditEvent{Actor: "alice", Action: action, Resource: "order/1", Outcome: AuditSuccess, Details: Fields{"amount": 10}}
+		if err := logger.Record(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readAuditLines returns the lines of the audit log.
+func readAuditLines(t *testing.T, path string) []string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+func TestAuditLoggerChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	config := AuditConfig{File: FileConfig{Path: path}, Key: "secret"}
+	writeAuditRecords(t, config, "order.create", "order.update")
+	// a new logger continues the chain of the file
+	writeAuditRecords(t, config, "order.delete")
+
+	lines := readAuditLines(t, path)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(lines))
+	}
+	last := AuditRecord{}
+	if err := json.Unmarshal([]byte(lines[2]), &last); err != nil {
+		t.Fatal(err)
+	}
+	if last.Seq != 3 || last.Action != "order.delete" || last.PrevHash == "" {
+		t.Fatalf("unexpected record %+v", last)
+	}
+	if err := VerifyAuditLog(strings.NewReader(strings.Join(lines, "\n")), "secret"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyAuditLogDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeAuditRecords(t, AuditConfig{File: FileConfig{Path: path}, Key: "secret"}, "order.create", "order.update", "order.delete")
+	lines := readAuditLines(t, path)
+
+	tests := []struct {
+		name  string
+		lines []string
+		key   string
+		err   string
+	}{
+		{"modified", []string{lines[0], strings.Replace(lines[1], `"alice"`, `"mallory"`, 1), lines[2]}, "secret", "audit record 2 on line 2 was modified"},
+		{"modified details", []string{lines[0], strings.Replace(lines[1], `"amount":10`, `"amount":1000`, 1), lines[2]}, "secret", "was modified"},
+		{"removed", []string{lines[0], lines[2]}, "secret", "audit record 3 on line 2 does not follow record 1"},
+		{"inserted", []string{lines[0], lines[1], lines[1], lines[2]}, "secret", "does not follow record 2"},
+		{"reordered", []string{lines[0], lines[2], lines[1]}, "secret", "does not follow record 1"},
+		{"wrong key", lines, "other", "audit record 1 on line 1 was modified"},
+		{"invalid", []string{lines[0], "{"}, "secret", "audit record on line 2 is invalid"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := VerifyAuditLog(strings.NewReader(strings.Join(test.lines, "\n")), test.key)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected the error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestNewAuditLoggerRejectsRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	tests := []struct {
+		name string
+		file FileConfig
+	}{
+		{"max backups", FileConfig{Path: path, MaxBackups: 3}},
+		{"max age", FileConfig{Path: path, MaxAge: 24 * time.Hour}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewAuditLogger(AuditConfig{File: test.file}); err == nil {
+				t.Fatal("expected the retention settings to be rejected")
+			}
+		})
+	}
+	if _, err := NewAuditLogger(AuditConfig{}); err == nil {
+		t.Fatal("expected an output to be required")
+	}
+}
+
+func TestAuditLoggerStdoutChain(t *testing.T) {
+	dir := t.TempDir()
+	stdout := filepath.Join(dir, "stdout.log")
+	file, err := os.Create(stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	previous := os.Stdout
+	os.Stdout = file
+	defer func() { os.Stdout = previous }()
+
+	state := filepath.Join(dir, "audit.state")
+	// the chain of the first two starts is continued from the state file
+	writeAuditRecords(t, AuditConfig{Stdout: true, StatePath: state}, "order.create")
+	writeAuditRecords(t, AuditConfig{Stdout: true, StatePath: state}, "order.update")
+	// without a state file, the chain restarts with a chain start record
+	writeAuditRecords(t, AuditConfig{Stdout: true}, "order.delete")
+
+	var actions []string
+	var seqs []uint64
+	for _, line := range readAuditLines(t, stdout) {
+		record := AuditRecord{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		actions = append(actions, record.Action)
+		seqs = append(seqs, record.Seq)
+	}
+	expected := []string{AuditChainStarted, "order.create", "order.update", AuditChainStarted, "order.delete"}
+	if strings.Join(actions, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected the actions %v, got %v", expected, actions)
+	}
+	if seqs[2] != 3 || seqs[3] != 1 {
+		t.Fatalf("unexpected sequence numbers %v", seqs)
+	}
+
+	content, err := os.ReadFile(stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyAuditLog(bytes.NewReader(content), ""); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,119 @@
+package log
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	// BaggageHeaderName is the W3C baggage header name
+	BaggageHeaderName = "baggage"
+	// BaggageKey is the key of the baggage in key-value stores such as gin.Context.
+	BaggageKey = "baggage"
+	// maxBaggageMembers is the maximum number of baggage members, as per the W3C baggage spec
+	maxBaggageMembers = 180
+	// maxBaggageLength is the maximum length of the baggage header, as per the W3C baggage spec
+	maxBaggageLength = 8192
+)
+
+// ContextWithBaggage returns a new context with the baggage fields added to the baggage of the context.
+// Baggage fields, such as tenant_id or user_id, are added to the loggers returned by ForRequest and
+// propagated to downstream services in the baggage header by the client package.
+// The context supplied is never modified.
+func ContextWithBaggage(ctx context.Context, baggage map[string]string) context.Context {
+	merged := BaggageFromContext(ctx)
+	if merged == nil {
+		merged = make(map[string]string, len(baggage))
+	}
+	for key, value := range baggage {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, baggageContextKey, merged)
+}
+
+// BaggageFromContext returns a copy of the baggage fields of the context.
+func BaggageFromContext(ctx context.Context) map[string]string {
+	baggage, ok := ctx.Value(baggageContextKey).(map[string]string)
+	if !ok {
+		baggage, _ = ctx.Value(BaggageKey).(map[string]string)
+	}
+	if len(baggage) == 0 {
+		return nil
+	}
+
+	copied := make(map[string]string, len(baggage))
+	for key, value := range baggage {
+		copied[key] = value
+	}
+	return copied
+}
+
+// FormatBaggage formats the baggage fields as a W3C baggage header value, sorted by key.
+// Invalid keys and the members exceeding the limits of the header are left out.
+func FormatBaggage(baggage map[string]string) string {
+	keys := make([]string, 0, len(baggage))
+	for key := range baggage {
+		if validBaggageKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var header strings.Builder
+	members := 0
+	for _, key := range keys {
+		member := key + "=" + url.PathEscape(baggage[key])
+		if members == maxBaggageMembers || header.Len()+len(member)+1 > maxBaggageLength {
+			break
+		}
+		if members > 0 {
+			header.WriteByte(',')
+		}
+		header.WriteString(member)
+		members++
+	}
+	return header.String()
+}
+
+// ParseBaggage parses a W3C baggage header value, ignoring the properties of the members and
+// the invalid members.
+func ParseBaggage(header string) map[string]string {
+	if header == "" || len(header) > maxBaggageLength {
+		return nil
+	}
+
+	baggage := map[string]string{}
+	for i, member := range strings.Split(header, ",") {
+		if i == maxBaggageMembers {
+			break
+		}
+		member, _, _ = strings.Cut(member, ";")
+		key, value, ok := strings.Cut(member, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !validBaggageKey(key) {
+			continue
+		}
+		value, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		baggage[key] = value
+	}
+	return baggage
+}
+
+// validBaggageKey returns true if the key is a token as per RFC 7230.
+func validBaggageKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c <= 0x20 || c >= 0x7f || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -2,10 +2,19 @@ package log
 
 // Config is the configuration for the logger.
 type Config struct {
-	LogLevel  string
-	LogFormat string
-	Program   string
-	Env       string
+	LogLevel   string
+	LogFormat  string
+	Program    string
+	Env        string
+	LogLevels  string
+	SpanEvents bool
+	File       FileConfig
+	Sampling   SamplingConfig
+	Redaction  RedactionConfig
+	Async      AsyncConfig
+	Syslog     SyslogConfig
+	Journald   JournaldConfig
+	HTTP       HTTPSinkConfig
 }
 
 // Level returns the log level.
@@ -27,3 +36,48 @@ func (c *Config) SourceProgram() string {
 func (c *Config) IsDev() bool {
 	return c.Env == "dev"
 }
+
+// NamedLogLevels returns the levels of the named loggers, e.g. "payments.*=debug,db=warn".
+func (c *Config) NamedLogLevels() string {
+	return c.LogLevels
+}
+
+// TraceSpanEvents returns true if request loggers record their entries as span events.
+func (c *Config) TraceSpanEvents() bool {
+	return c.SpanEvents
+}
+
+// FileSink returns the file sink configuration.
+func (c *Config) FileSink() FileConfig {
+	return c.File
+}
+
+// SamplingPolicy returns the sampling, rate limiting and duplicate suppression configuration.
+func (c *Config) SamplingPolicy() SamplingConfig {
+	return c.Sampling
+}
+
+// RedactionPolicy returns the configuration for redacting secrets and PII.
+func (c *Config) RedactionPolicy() RedactionConfig {
+	return c.Redaction
+}
+
+// AsyncPolicy returns the configuration for asynchronous buffered logging.
+func (c *Config) AsyncPolicy() AsyncConfig {
+	return c.Async
+}
+
+// SyslogSink returns the configuration of the syslog sink
+func (c *Config) SyslogSink() SyslogConfig {
+	return c.Syslog
+}
+
+// JournaldSink returns the configuration of the journald sink
+func (c *Config) JournaldSink() JournaldConfig {
+	return c.Journald
+}
+
+// HTTPSink returns the configuration of the HTTP sink
+func (c *Config) HTTPSink() HTTPSinkConfig {
+	return c.HTTP
+}
@@ -2,12 +2,29 @@ package log
 
 import "context"
 
+const (
+	// LoggerKey is the key of the request logger in key-value stores such as gin.Context, set by
+	// the sapi middlewares and read by ContextLogger.
+	LoggerKey = "logger"
+	// RequestFieldsKey is the key of the request fields carried by the request logger in key-value
+	// stores such as gin.Context, read by ForRequest so it does not add them again.
+	RequestFieldsKey = "request_fields"
+)
+
 // contextKey is the type for context keys.
 type contextKey int
 
 const (
 	// loggerContextKey is the context key for the logger.
 	loggerContextKey contextKey = iota
+	// traceparentContextKey is the context key for the W3C traceparent header value.
+	traceparentContextKey
+	// baggageContextKey is the context key for the baggage fields.
+	baggageContextKey
+	// requestIDContextKey is the context key for the request id.
+	requestIDContextKey
+	// requestFieldsContextKey is the context key for the request fields of the stored logger.
+	requestFieldsContextKey
 )
 
 // ContextLogger returns the logger stored in context or a new logger.
@@ -15,11 +32,16 @@ func ContextLogger(ctx context.Context) Logger {
 	if logger, ok := ctx.Value(loggerContextKey).(Logger); ok {
 		return logger
 	}
+	if logger, ok := ctx.Value(LoggerKey).(Logger); ok {
+		return logger
+	}
 
 	return Global()
 }
 
 // ContextWithLogger returns a new context with the logger.
+// The context supplied is never modified, a gin.Context stays as is and the logger is only
+// stored in the returned context.
 func ContextWithLogger(ctx context.Context, logger Logger) context.Context {
 	return context.WithValue(ctx, loggerContextKey, logger)
 }
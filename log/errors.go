@@ -0,0 +1,161 @@
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// maxErrorDepth is the maximum depth of the error chains walked
+const maxErrorDepth = 32
+
+// FieldsError is an error carrying structured attributes, which are logged as fields with the error.
+type FieldsError interface {
+	error
+	LogFields() Fields
+}
+
+// stackError is an error carrying the stack trace where it was wrapped.
+type stackError struct {
+	err   error
+	stack []uintptr
+}
+
+// WithStack returns the error carrying the stack trace of the caller, or nil if the error is nil.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+	stack := make([]uintptr, 64)
+	return &stackError{err: err, stack: stack[:runtime.Callers(2, stack)]}
+}
+
+// Error returns the message of the wrapped error.
+func (e *stackError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *stackError) Unwrap() error {
+	return e.err
+}
+
+// StackTrace returns the program counters of the stack trace.
+func (e *stackError) StackTrace() []uintptr {
+	return e.stack
+}
+
+// errorCause is a cause of a logged error.
+type errorCause struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// ErrorFields returns the fields logging the error under the key: the message, its type, the
+// causes of its Unwrap and Join chains, the deepest stack trace carried by the chain and the
+// attributes of the errors implementing FieldsError, outer errors taking precedence.
+func ErrorFields(key string, err error) []Field {
+	if err == nil {
+		return nil
+	}
+
+	fields := []Field{
+		String(key, err.Error()),
+		String(key+"_type", fmt.Sprintf("%T", err)),
+	}
+
+	var causes []errorCause
+	var stack string
+	stackDepth := -1
+	var attributes []Fields
+	walkError(err, 0, func(current error, depth int) {
+		if _, isStack := current.(*stackError); depth > 0 && !isStack {
+			causes = append(causes, errorCause{current.Error(), fmt.Sprintf("%T", current)})
+		}
+		// the innermost stack trace is the closest to where the error occurred
+		if trace := errorStack(current); trace != "" && depth > stackDepth {
+			stack, stackDepth = trace, depth
+		}
+		if fieldsErr, ok := current.(FieldsError); ok {
+			attributes = append(attributes, fieldsErr.LogFields())
+		}
+	})
+
+	if len(causes) > 0 {
+		fields = append(fields, Any(key+"_causes", causes))
+	}
+	if stack != "" {
+		fields = append(fields, String(key+"_stack", stack))
+	}
+
+	merged := Fields{}
+	for i := len(attributes) - 1; i >= 0; i-- {
+		for name, value := range attributes[i] {
+			merged[name] = value
+		}
+	}
+	names := make([]string, 0, len(merged))
+	for name := range merged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields = append(fields, Any(name, merged[name]))
+	}
+
+	return fields
+}
+
+// walkError calls visit for the error and, depth first, for the errors of its Unwrap, Join and Cause chains.
+func walkError(err error, depth int, visit func(err error, depth int)) {
+	if err == nil || depth > maxErrorDepth {
+		return
+	}
+	visit(err, depth)
+
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, cause := range wrapped.Unwrap() {
+			walkError(cause, depth+1, visit)
+		}
+	case interface{ Unwrap() error }:
+		walkError(wrapped.Unwrap(), depth+1, visit)
+	// github.com/pkg/errors before v0.9.0 only implements Cause
+	case interface{ Cause() error }:
+		walkError(wrapped.Cause(), depth+1, visit)
+	}
+}
+
+// errorStack returns the formatted stack trace of an error returned by WithStack or
+// github.com/pkg/errors, or of an error with a StackTrace method returning a string.
+func errorStack(err error) string {
+	var pcs []uintptr
+	switch traced := err.(type) {
+	case interface{ StackTrace() []uintptr }:
+		pcs = traced.StackTrace()
+	case interface{ StackTrace() pkgerrors.StackTrace }:
+		for _, frame := range traced.StackTrace() {
+			pcs = append(pcs, uintptr(frame))
+		}
+	case interface{ StackTrace() string }:
+		return traced.StackTrace()
+	default:
+		return ""
+	}
+
+	var builder strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			fmt.Fprintf(&builder, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
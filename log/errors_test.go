@@ -0,0 +1,59 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// stringStackError is an error with a StackTrace method returning a string.
+type stringStackError struct{}
+
+func (stringStackError) Error() string {
+	return "failed"
+}
+
+func (stringStackError) StackTrace() string {
+	return "main.main\n\t/app/main.go:5"
+}
+
+// newInnerStackError returns an error carrying the stack trace of this function.
+func newInnerStackError() error {
+	return WithStack(errors.New("failed"))
+}
+
+func TestErrorStack(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"no stack", errors.New("failed"), ""},
+		{"WithStack", WithStack(errors.New("failed")), "log.TestErrorStack"},
+		{"pkg/errors", pkgerrors.New("failed"), "log.TestErrorStack"},
+		{"string", stringStackError{}, "main.main"},
+		{"innermost", WithStack(fmt.Errorf("wrapped, %w", newInnerStackError())), "log.newInnerStackError"},
+		{"pkg/errors wrapping", pkgerrors.Wrap(newInnerStackError(), "wrapped"), "log.newInnerStackError"},
+		{"joined", errors.Join(errors.New("first"), fmt.Errorf("wrapped, %w", newInnerStackError())), "log.newInnerStackError"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stack string
+			for _, field := range ErrorFields("error", test.err) {
+				if field.Key == "error_stack" {
+					stack = field.Value.(string)
+				}
+			}
+			if test.expected == "" && stack != "" {
+				t.Fatalf("expected no stack, got %q", stack)
+			}
+			if firstFrame, _, _ := strings.Cut(stack, "\n"); !strings.HasSuffix(firstFrame, test.expected) {
+				t.Fatalf("expected the stack to start in %s, got %q", test.expected, stack)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field is a key value pair attached to a log entry, independent of the logging backend.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a Field with a string value.
+func String(key string, value string) Field {
+	return Field{key, value}
+}
+
+// Int returns a Field with an int value.
+func Int(key string, value int) Field {
+	return Field{key, value}
+}
+
+// Int64 returns a Field with an int64 value.
+func Int64(key string, value int64) Field {
+	return Field{key, value}
+}
+
+// Float64 returns a Field with a float64 value.
+func Float64(key string, value float64) Field {
+	return Field{key, value}
+}
+
+// Bool returns a Field with a bool value.
+func Bool(key string, value bool) Field {
+	return Field{key, value}
+}
+
+// Duration returns a Field with a time.Duration value.
+func Duration(key string, value time.Duration) Field {
+	return Field{key, value}
+}
+
+// Time returns a Field with a time.Time value.
+func Time(key string, value time.Time) Field {
+	return Field{key, value}
+}
+
+// Err returns a Field with the error under the "error" key.
+// The error is logged with its causes, stack trace and attributes, see ErrorFields.
+func Err(err error) Field {
+	return Field{"error", err}
+}
+
+// Any returns a Field with an arbitrary value.
+func Any(key string, value interface{}) Field {
+	return Field{key, value}
+}
+
+// Lazy returns a Field whose value is computed only if the entry is written.
+func Lazy(key string, value func() interface{}) Field {
+	return Field{key, lazyValue(value)}
+}
+
+// Stringf returns a Field with a string value formatted only if the entry is written.
+func Stringf(key string, format string, args ...interface{}) Field {
+	return Lazy(key, func() interface{} {
+		return fmt.Sprintf(format, args...)
+	})
+}
+
+// Stringer returns a Field with the string value of the fmt.Stringer, computed only if the entry is written.
+func Stringer(key string, value fmt.Stringer) Field {
+	return Lazy(key, func() interface{} {
+		return value.String()
+	})
+}
+
+// ZapField returns a Field wrapping a zap field, for callers still using zap constructors.
+func ZapField(field zapcore.Field) Field {
+	return Field{field.Key, field}
+}
+
+// zapField converts the Field to a zap field.
+func (f Field) zapField() zapcore.Field {
+	if field, ok := f.Value.(zapcore.Field); ok {
+		return field
+	}
+	return zap.Any(f.Key, f.Value)
+}
+
+// zapFields converts the fields to zap fields, expanding errors into their fields.
+func zapFields(fields []Field) []zapcore.Field {
+	if len(fields) == 0 {
+		return nil
+	}
+	converted := make([]zapcore.Field, 0, len(fields))
+	for _, field := range fields {
+		if err, ok := field.Value.(error); ok {
+			converted = append(converted, zapFields(ErrorFields(field.Key, err))...)
+			continue
+		}
+		converted = append(converted, field.zapField())
+	}
+	return converted
+}
+
+// Resolved returns the value of the Field, resolving wrapped zap fields and lazy values.
+func (f Field) Resolved() interface{} {
+	if lazy, ok := f.Value.(lazyValue); ok {
+		return lazy()
+	}
+	field, ok := f.Value.(zapcore.Field)
+	if !ok {
+		return f.Value
+	}
+	encoder := zapcore.NewMapObjectEncoder()
+	field.AddTo(encoder)
+	return encoder.Fields[field.Key]
+}
+
+// lazyValue is a value computed when it is encoded.
+type lazyValue func() interface{}
+
+// MarshalJSON encodes the computed value.
+func (v lazyValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v())
+}
+
+// LogValue returns the computed value, so slog handlers compute it only when the record is handled.
+func (v lazyValue) LogValue() slog.Value {
+	return slog.AnyValue(v())
+}
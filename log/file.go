@@ -0,0 +1,456 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// backupTimeFormat is the UTC timestamp layout used in rotated file names, followed by a
+	// counter when several files are rotated within the same millisecond
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	// compressSuffix is the suffix appended to compressed backups
+	compressSuffix = ".gz"
+	// megabyte is the number of bytes in a megabyte
+	megabyte = 1024 * 1024
+)
+
+// FileConfig is the configuration for the rotating file sink.
+type FileConfig struct {
+	// Path is the file to write logs to. The file sink is disabled when empty.
+	Path string
+	// MaxSize is the maximum size in megabytes of the file before it is rotated.
+	// Size based rotation is disabled when zero.
+	MaxSize int
+	// RotateInterval rotates the file when the interval boundary is crossed.
+	// Time based rotation is disabled when zero.
+	RotateInterval time.Duration
+	// MaxBackups is the maximum number of rotated files to retain. Zero retains all.
+	MaxBackups int
+	// MaxAge is the maximum age of rotated files to retain. Zero retains all.
+	MaxAge time.Duration
+	// Compress determines whether rotated files are gzip compressed.
+	Compress bool
+	// ReopenOnSIGHUP reopens the file when the process receives SIGHUP,
+	// so it can cooperate with an external logrotate.
+	ReopenOnSIGHUP bool
+}
+
+// Enabled returns true if the file sink is configured.
+func (c FileConfig) Enabled() bool {
+	return c.Path != ""
+}
+
+// FileSink is a zapcore.WriteSyncer writing to a file with rotation by size and/or time.
+type FileSink struct {
+	config FileConfig
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	millOnce sync.Once
+	millCh   chan struct{}
+	signals  chan os.Signal
+	done     chan struct{}
+}
+
+// NewFileSink creates a new FileSink and opens the configured file.
+func NewFileSink(config FileConfig) (*FileSink, error) {
+	if !config.Enabled() {
+		return nil, fmt.Errorf("log file path is not configured")
+	}
+
+	sink := &FileSink{
+		config: config,
+		done:   make(chan struct{}),
+	}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+
+	if config.ReopenOnSIGHUP {
+		sink.signals = make(chan os.Signal, 1)
+		signal.Notify(sink.signals, syscall.SIGHUP)
+		go sink.watchSignals()
+	}
+
+	return sink, nil
+}
+
+// Write writes the bytes to the file, rotating it first if required.
+// It returns os.ErrClosed once the sink is closed.
+func (s *FileSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed() {
+		return 0, os.ErrClosed
+	}
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if s.shouldRotate(int64(len(p))) {
+		if err := s.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := s.file.Write(p)
+	s.size += int64(n)
+	return n, err
+}
+
+// Sync commits the current contents of the file to stable storage.
+func (s *FileSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Sync()
+}
+
+// Rotate closes the current file, moves it aside and opens a new one.
+func (s *FileSink) Rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed() {
+		return os.ErrClosed
+	}
+	return s.rotate()
+}
+
+// Reopen closes and reopens the file at the configured path.
+// It is used after the file has been moved by an external tool such as logrotate.
+func (s *FileSink) Reopen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed() {
+		return os.ErrClosed
+	}
+	if err := s.close(); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+// Close closes the file and stops listening for signals.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed() {
+		close(s.done)
+		if s.signals != nil {
+			signal.Stop(s.signals)
+		}
+	}
+
+	return s.close()
+}
+
+// closed returns true once the sink is closed.
+func (s *FileSink) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// watchSignals reopens the file every time SIGHUP is received.
+func (s *FileSink) watchSignals() {
+	for {
+		select {
+		case <-s.signals:
+			if err := s.Reopen(); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to reopen the log file %s, %s\n", s.config.Path, err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// shouldRotate returns true if writing n bytes requires the file to be rotated first.
+func (s *FileSink) shouldRotate(n int64) bool {
+	if s.config.MaxSize > 0 && s.size > 0 && s.size+n > int64(s.config.MaxSize)*megabyte {
+		return true
+	}
+
+	if s.config.RotateInterval > 0 {
+		boundary := s.openedAt.Truncate(s.config.RotateInterval).Add(s.config.RotateInterval)
+		return !time.Now().Before(boundary)
+	}
+
+	return false
+}
+
+// open opens the configured file for appending, creating it if necessary.
+func (s *FileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.config.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create the log directory, %w", err)
+	}
+
+	file, err := os.OpenFile(s.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open the log file, %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat the log file, %w", err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+	s.openedAt = time.Now()
+	if info.Size() > 0 {
+		s.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// close closes the current file if it is open.
+func (s *FileSink) close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// rotate moves the current file to a timestamped backup and opens a new file.
+func (s *FileSink) rotate() error {
+	if err := s.close(); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(s.config.Path); err == nil {
+		if err := os.Rename(s.config.Path, s.backupName(time.Now())); err != nil {
+			return fmt.Errorf("failed to rotate the log file, %w", err)
+		}
+	}
+
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	s.triggerMill()
+	return nil
+}
+
+// backupName returns the name of the backup file for the given rotation time, adding a counter
+// if a backup of the same millisecond exists.
+func (s *FileSink) backupName(t time.Time) string {
+	dir, prefix, ext := s.nameParts()
+	timestamp := t.UTC().Format(backupTimeFormat)
+	name := filepath.Join(dir, prefix+timestamp+ext)
+	for counter := 1; backupExists(name); counter++ {
+		name = filepath.Join(dir, fmt.Sprintf("%s%s-%d%s", prefix, timestamp, counter, ext))
+	}
+	return name
+}
+
+// backupExists returns true if the backup, or its compressed copy, exists.
+func backupExists(name string) bool {
+	for _, path := range []string{name, name + compressSuffix} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// nameParts splits the configured path into the directory, backup prefix and extension.
+func (s *FileSink) nameParts() (string, string, string) {
+	dir := filepath.Dir(s.config.Path)
+	base := filepath.Base(s.config.Path)
+	ext := filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// triggerMill requests the backups to be compressed and cleaned up in the background.
+func (s *FileSink) triggerMill() {
+	if s.config.MaxBackups == 0 && s.config.MaxAge == 0 && !s.config.Compress {
+		return
+	}
+
+	s.millOnce.Do(func() {
+		s.millCh = make(chan struct{}, 1)
+		go s.millRun()
+	})
+
+	select {
+	case s.millCh <- struct{}{}:
+	default:
+	}
+}
+
+// millRun runs the mill every time it is triggered until the sink is closed.
+func (s *FileSink) millRun() {
+	for {
+		select {
+		case <-s.millCh:
+			if err := s.mill(); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to clean up the log files of %s, %s\n", s.config.Path, err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// backupFile is a rotated log file.
+type backupFile struct {
+	path      string
+	timestamp time.Time
+	counter   int
+}
+
+// mill removes the backups exceeding the retention policy and compresses the remaining ones.
+func (s *FileSink) mill() error {
+	backups, err := s.backups()
+	if err != nil {
+		return err
+	}
+
+	var remaining []backupFile
+	cutoff := time.Now().Add(-s.config.MaxAge)
+	for i, backup := range backups {
+		expired := s.config.MaxAge > 0 && backup.timestamp.Before(cutoff)
+		exceeded := s.config.MaxBackups > 0 && i >= s.config.MaxBackups
+		if expired || exceeded {
+			if err := os.Remove(backup.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		remaining = append(remaining, backup)
+	}
+
+	if !s.config.Compress {
+		return nil
+	}
+
+	for _, backup := range remaining {
+		if strings.HasSuffix(backup.path, compressSuffix) {
+			continue
+		}
+		if err := compressFile(backup.path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backups returns the rotated files sorted from newest to oldest.
+func (s *FileSink) backups() ([]backupFile, error) {
+	dir, prefix, ext := s.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backupFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), compressSuffix)
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		timestamp, counter, ok := parseBackupTime(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if !ok {
+			continue
+		}
+		backups = append(backups, backupFile{filepath.Join(dir, entry.Name()), timestamp, counter})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].timestamp.Equal(backups[j].timestamp) {
+			return backups[i].timestamp.After(backups[j].timestamp)
+		}
+		return backups[i].counter > backups[j].counter
+	})
+	return backups, nil
+}
+
+// parseBackupTime parses the UTC timestamp and the optional counter of a backup name.
+func parseBackupTime(value string) (time.Time, int, bool) {
+	if len(value) < len(backupTimeFormat) {
+		return time.Time{}, 0, false
+	}
+	timestamp, err := time.Parse(backupTimeFormat, value[:len(backupTimeFormat)])
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+
+	counter := 0
+	if suffix := value[len(backupTimeFormat):]; suffix != "" {
+		if !strings.HasPrefix(suffix, "-") {
+			return time.Time{}, 0, false
+		}
+		if counter, err = strconv.Atoi(suffix[1:]); err != nil || counter < 1 {
+			return time.Time{}, 0, false
+		}
+	}
+	return timestamp, counter, true
+}
+
+// compressFile gzips the file and removes the original.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(path + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	_ = src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,162 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileSinkRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	sink, err := NewFileSink(FileConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := sink.Write([]byte("entry\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := sink.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 3 {
+		t.Fatalf("expected 3 backups, got %d", len(backups))
+	}
+	seen := map[string]bool{}
+	for _, backup := range backups {
+		if seen[backup.path] {
+			t.Fatalf("backup %s is listed twice", backup.path)
+		}
+		seen[backup.path] = true
+		content, err := os.ReadFile(backup.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != "entry\n" {
+			t.Fatalf("unexpected content of %s: %q", backup.path, content)
+		}
+	}
+}
+
+func TestFileSinkBackupName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	sink := &FileSink{config: FileConfig{Path: path}}
+	now := time.Date(2024, 3, 1, 23, 30, 0, 123e6, time.FixedZone("UTC+2", 2*60*60))
+
+	first := sink.backupName(now)
+	if filepath.Base(first) != "app-2024-03-01T21-30-00.123.log" {
+		t.Fatalf("unexpected backup name %s", first)
+	}
+	if err := os.WriteFile(first, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	second := sink.backupName(now)
+	if filepath.Base(second) != "app-2024-03-01T21-30-00.123-1.log" {
+		t.Fatalf("unexpected backup name %s", second)
+	}
+	if err := os.WriteFile(second, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := sink.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 || backups[0].path != second || backups[1].path != first {
+		t.Fatalf("unexpected backups %v", backups)
+	}
+	if !backups[0].timestamp.Equal(now) {
+		t.Fatalf("expected the backup time %s, got %s", now, backups[0].timestamp)
+	}
+}
+
+func TestFileSinkMill(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	sink := &FileSink{config: FileConfig{Path: path, MaxBackups: 2, MaxAge: time.Hour, Compress: true}}
+
+	now := time.Now()
+	var names []string
+	for _, age := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 2 * time.Hour} {
+		name := sink.backupName(now.Add(-age))
+		if err := os.WriteFile(name, []byte("entry\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+
+	if err := sink.mill(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, name := range names {
+		_, err := os.Stat(name + compressSuffix)
+		if kept := i < 2; kept != (err == nil) {
+			t.Fatalf("backup %d: expected kept %t, got %v", i, kept, err)
+		}
+		if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("backup %d was not removed or compressed", i)
+		}
+	}
+}
+
+func TestFileSinkRotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	sink, err := NewFileSink(FileConfig{Path: path, MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	line := []byte(strings.Repeat("x", megabyte/2) + "\n")
+	for i := 0; i < 3; i++ {
+		if _, err := sink.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := sink.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each line is over half the maximum size, so each file holds a single line
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %d", len(backups))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != int64(len(line)) {
+		t.Fatalf("expected the file to hold a single line, got %d bytes", info.Size())
+	}
+}
+
+func TestFileSinkWriteAfterClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	sink, err := NewFileSink(FileConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sink.Write([]byte("entry\n")); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("expected os.ErrClosed, got %v", err)
+	}
+	if err := sink.Reopen(); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("expected os.ErrClosed, got %v", err)
+	}
+}
@@ -1,9 +1,12 @@
 module github.com/s3ndd/sen-go/log
 
-go 1.20
+go 1.21
 
 require (
+	github.com/pkg/errors v0.8.1
 	github.com/s3ndd/sen-go/config v0.0.0-20230522201247-6b1fce2ea6f7
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
 	go.uber.org/zap v1.24.0
 )
 
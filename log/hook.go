@@ -0,0 +1,198 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// HookEntry is a log entry passed to the hooks.
+type HookEntry struct {
+	Level      Level
+	Time       time.Time
+	Message    string
+	LoggerName string
+	// Caller is the file:line the entry was logged from.
+	Caller string
+	// Stack is the stack trace of the entry, captured at error level and above.
+	Stack string
+	// Fields are the redacted fields of the entry, including those of the logger.
+	Fields map[string]interface{}
+}
+
+// Hook receives the entries logged at its levels, e.g. to forward them to an error tracker.
+// Entries dropped by sampling, rate limiting or duplicate suppression are not passed to the hooks.
+// Fire is called synchronously, so hooks doing I/O should buffer the entries and implement
+// Sync or io.Closer, which are called by the package Sync and Close.
+type Hook interface {
+	Levels() []Level
+	Fire(entry HookEntry) error
+}
+
+// hookRegistry holds the hooks of every logger.
+type hookRegistry struct {
+	mu    sync.RWMutex
+	hooks []*registeredHook
+}
+
+// registeredHook is a registered hook, identified by its address so it can be removed.
+type registeredHook struct {
+	Hook
+}
+
+// hooks is the registry of the hooks
+var hooks = &hookRegistry{}
+
+// AddHook registers a hook fired by every logger, and returns a function removing it.
+func AddHook(hook Hook) (remove func()) {
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+
+	registered := &registeredHook{hook}
+	hooks.hooks = append(hooks.hooks, registered)
+	return func() {
+		hooks.mu.Lock()
+		defer hooks.mu.Unlock()
+
+		for i, current := range hooks.hooks {
+			if current == registered {
+				hooks.hooks = append(hooks.hooks[:i:i], hooks.hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// enabled returns true if a hook fires at the level.
+func (r *hookRegistry) enabled(level zapcore.Level) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, hook := range r.hooks {
+		if hookFiresAt(hook, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// fire fires the hooks at the level of the entry.
+func (r *hookRegistry) fire(level zapcore.Level, entry HookEntry) error {
+	r.mu.RLock()
+	registered := append([]*registeredHook{}, r.hooks...)
+	r.mu.RUnlock()
+
+	var errs []error
+	for _, hook := range registered {
+		if hookFiresAt(hook, level) {
+			errs = append(errs, hook.Fire(entry))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync syncs the hooks implementing Sync.
+func (r *hookRegistry) Sync() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var errs []error
+	for _, hook := range r.hooks {
+		if syncer, ok := hook.Hook.(syncer); ok {
+			errs = append(errs, syncer.Sync())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the hooks implementing io.Closer.
+func (r *hookRegistry) Close() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var errs []error
+	for _, hook := range r.hooks {
+		if closer, ok := hook.Hook.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hookFiresAt returns true if the hook fires at the level.
+func hookFiresAt(hook Hook, level zapcore.Level) bool {
+	for _, hookLevel := range hook.Levels() {
+		if zapLevel, err := hookLevel.zapLevel(); err == nil && zapLevel == level {
+			return true
+		}
+	}
+	return false
+}
+
+// hookCore is a zapcore.Core firing the registered hooks.
+type hookCore struct {
+	fields []zapcore.Field
+}
+
+// Enabled returns true if a hook fires at the level.
+func (c *hookCore) Enabled(level zapcore.Level) bool {
+	return hooks.enabled(level)
+}
+
+// With returns a core passing the fields to the hooks with every entry.
+func (c *hookCore) With(fields []zapcore.Field) zapcore.Core {
+	return &hookCore{fields: append(append([]zapcore.Field{}, c.fields...), fields...)}
+}
+
+// Check adds the core to the checked entry if a hook fires at the level.
+func (c *hookCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write fires the hooks with the entry. Hook errors are reported to stderr, so a failing
+// hook does not fail the other outputs.
+func (c *hookCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(encoder)
+	}
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+
+	hookEntry := HookEntry{
+		Level:      levelFromZap(entry.Level),
+		Time:       entry.Time,
+		Message:    entry.Message,
+		LoggerName: entry.LoggerName,
+		Stack:      entry.Stack,
+		Fields:     encoder.Fields,
+	}
+	if entry.Caller.Defined {
+		hookEntry.Caller = entry.Caller.TrimmedPath()
+	}
+
+	if err := hooks.fire(entry.Level, hookEntry); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to fire the log hooks, %s\n", err)
+	}
+	// the process exits or panics after fatal and panic entries, so the hooks are flushed first
+	if entry.Level > zapcore.ErrorLevel {
+		if err := hooks.Sync(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to sync the log hooks, %s\n", err)
+		}
+	}
+	return nil
+}
+
+// Sync syncs the hooks.
+func (c *hookCore) Sync() error {
+	return hooks.Sync()
+}
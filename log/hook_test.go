@@ -0,0 +1,54 @@
+package log
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingHook is a hook keeping the entries it receives.
+type recordingHook struct {
+	mu      sync.Mutex
+	entries []HookEntry
+}
+
+func (h *recordingHook) Levels() []Level {
+	return []Level{LevelError}
+}
+
+func (h *recordingHook) Fire(entry HookEntry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, entry)
+	return nil
+}
+
+func TestHooksAreSampledAndRedacted(t *testing.T) {
+	hook := &recordingHook{}
+	remove := AddHook(hook)
+	defer remove()
+
+	logger := NewZapLogger(&Config{
+		LogLevel:  "info",
+		LogFormat: "json",
+		File:      FileConfig{Path: filepath.Join(t.TempDir(), "app.log")},
+		Redaction: RedactionConfig{Enabled: true},
+		Sampling:  SamplingConfig{Initial: 1, Thereafter: 100, Interval: time.Minute},
+	})
+	defer logger.(*ZapLogger).Close()
+
+	for i := 0; i < 3; i++ {
+		logger.WithField("password", "hunter2").Error("failed to sign in")
+	}
+	logger.Info("signed in")
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+	if len(hook.entries) != 1 {
+		t.Fatalf("expected 1 hook entry, got %d", len(hook.entries))
+	}
+	if entry := hook.entries[0]; entry.Message != "failed to sign in" || entry.Fields["password"] != redactedPlaceholder {
+		t.Fatalf("unexpected hook entry %+v", entry)
+	}
+}
@@ -0,0 +1,406 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HTTPSinkFormat is the request body format of the HTTP sink.
+type HTTPSinkFormat string
+
+const (
+	// HTTPFormatJSON posts the entries as a JSON array.
+	HTTPFormatJSON HTTPSinkFormat = "json"
+	// HTTPFormatLoki posts the entries to the Loki push API as a single stream.
+	HTTPFormatLoki HTTPSinkFormat = "loki"
+	// HTTPFormatElasticsearch posts the entries to the Elasticsearch bulk API.
+	HTTPFormatElasticsearch HTTPSinkFormat = "elasticsearch"
+)
+
+// HTTPSinkConfig is the configuration for the batching HTTP sink.
+type HTTPSinkConfig struct {
+	// URL is the endpoint the batches are posted to. The HTTP sink is disabled when empty.
+	URL string
+	// Format is the request body format, json by default.
+	Format HTTPSinkFormat
+	// BatchSize is the number of entries posted in a single request.
+	BatchSize int
+	// FlushInterval is the maximum time entries wait before being posted.
+	FlushInterval time.Duration
+	// MaxPending is the number of entries waiting to be posted before new entries are dropped.
+	MaxPending int
+	// MaxRetries is the number of times a failed request is retried.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled for each retry.
+	RetryBackoff time.Duration
+	// Timeout is the timeout of each request.
+	Timeout time.Duration
+	// FlushTimeout is how long Sync and Close wait for the pending entries to be posted, 5s by default.
+	FlushTimeout time.Duration
+	// Gzip compresses the request bodies.
+	Gzip bool
+	// Headers are added to each request, e.g. Authorization.
+	Headers map[string]string
+	// Labels are the labels of the Loki stream.
+	Labels map[string]string
+	// Index is the Elasticsearch index.
+	Index string
+	// Client is the HTTP client posting the batches, a client with the timeout by default.
+	Client *http.Client
+}
+
+// Enabled returns true if the HTTP sink is configured.
+func (c HTTPSinkConfig) Enabled() bool {
+	return c.URL != ""
+}
+
+// HTTPSink is a zapcore.WriteSyncer posting entries in batches from a background goroutine,
+// retrying failed requests.
+type HTTPSink struct {
+	config HTTPSinkConfig
+	client *http.Client
+
+	mu      sync.Mutex
+	pending []sinkEntry
+	closed  bool
+
+	sending chan struct{}
+	wake    chan struct{}
+	done    chan struct{}
+	stop    sync.Once
+	wg      sync.WaitGroup
+
+	// ctx is canceled on Close, so the background goroutine stops retrying
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewHTTPSink creates a new HTTPSink and starts its background goroutine.
+func NewHTTPSink(config HTTPSinkConfig) (*HTTPSink, error) {
+	switch config.Format {
+	case "":
+		config.Format = HTTPFormatJSON
+	case HTTPFormatJSON, HTTPFormatLoki:
+	case HTTPFormatElasticsearch:
+		if config.Index == "" {
+			return nil, errors.New("the elasticsearch index is not configured")
+		}
+	default:
+		return nil, fmt.Errorf("invalid HTTP log sink format %q", config.Format)
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = config.BatchSize * 100
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.FlushTimeout <= 0 {
+		config.FlushTimeout = 5 * time.Second
+	}
+
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: config.Timeout}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := &HTTPSink{
+		config:  config,
+		client:  client,
+		sending: make(chan struct{}, 1),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	sink.wg.Add(1)
+	go sink.run()
+	return sink, nil
+}
+
+// Write queues the encoded entries, dropping them if too many entries are pending.
+func (s *HTTPSink) Write(p []byte) (int, error) {
+	entries := parseSinkEntries(p)
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return 0, errors.New("HTTP log sink is closed")
+	}
+	if room := s.config.MaxPending - len(s.pending); len(entries) > room {
+		dropped.httpSink.Add(uint64(len(entries) - room))
+		entries = entries[:room]
+	}
+	s.pending = append(s.pending, entries...)
+	full := len(s.pending) >= s.config.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// Sync posts the pending entries, waiting at most the flush timeout.
+func (s *HTTPSink) Sync() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.FlushTimeout)
+	defer cancel()
+
+	return s.flush(ctx, s.config.MaxRetries)
+}
+
+// Close stops the background goroutine and posts the pending entries once, without retrying,
+// waiting at most the flush timeout.
+func (s *HTTPSink) Close() error {
+	s.stop.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+		s.cancel()
+		close(s.done)
+	})
+	s.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.FlushTimeout)
+	defer cancel()
+
+	return s.flush(ctx, 0)
+}
+
+// run posts the pending entries every flush interval, or sooner when a batch is full.
+func (s *HTTPSink) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		case <-s.done:
+			return
+		}
+		if err := s.flush(s.ctx, s.config.MaxRetries); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to post the log entries, %s\n", err)
+		}
+	}
+}
+
+// flush posts the pending entries in batches, retrying each batch up to the retries, until
+// the context is done.
+func (s *HTTPSink) flush(ctx context.Context, retries int) error {
+	select {
+	case s.sending <- struct{}{}:
+		defer func() { <-s.sending }()
+	case <-ctx.Done():
+		return fmt.Errorf("failed to post the log entries, %w", ctx.Err())
+	}
+
+	var errs []error
+	for ctx.Err() == nil {
+		s.mu.Lock()
+		size := len(s.pending)
+		if size > s.config.BatchSize {
+			size = s.config.BatchSize
+		}
+		batch := s.pending[:size:size]
+		s.pending = s.pending[size:]
+		if len(s.pending) == 0 {
+			s.pending = nil
+		}
+		s.mu.Unlock()
+
+		if len(batch) == 0 {
+			return errors.Join(errs...)
+		}
+		errs = append(errs, s.post(ctx, batch, retries))
+	}
+	return errors.Join(append(errs, fmt.Errorf("failed to post the log entries, %w", ctx.Err()))...)
+}
+
+// post posts the batch, retrying with exponential backoff on network errors, 429 and 5xx responses.
+func (s *HTTPSink) post(ctx context.Context, batch []sinkEntry, retries int) error {
+	body, contentType, err := s.encode(batch)
+	if err != nil {
+		return err
+	}
+
+	backoff := s.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := s.send(ctx, body, contentType)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= retries {
+			return fmt.Errorf("failed to post %d log entries, %w", len(batch), err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("failed to post %d log entries, %w", len(batch), ctx.Err())
+		}
+		backoff *= 2
+	}
+}
+
+// send sends a single request and returns whether it may be retried if it failed.
+func (s *HTTPSink) send(ctx context.Context, body []byte, contentType string) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", contentType)
+	if s.config.Gzip {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
+	for key, value := range s.config.Headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusOK && response.StatusCode < http.StatusMultipleChoices {
+		if s.config.Format == HTTPFormatElasticsearch {
+			return false, bulkError(response.Body)
+		}
+		_, _ = io.Copy(io.Discard, response.Body)
+		return false, nil
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
+	return retry, fmt.Errorf("unexpected status %s", response.Status)
+}
+
+// bulkError returns an error if the Elasticsearch bulk response reports rejected documents.
+func bulkError(body io.Reader) error {
+	var response struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return fmt.Errorf("invalid bulk response, %w", err)
+	}
+	if !response.Errors {
+		return nil
+	}
+
+	rejected, reason := 0, ""
+	for _, item := range response.Items {
+		for _, result := range item {
+			if result.Status >= http.StatusMultipleChoices {
+				if rejected == 0 {
+					reason = fmt.Sprintf("%s: %s", result.Error.Type, result.Error.Reason)
+				}
+				rejected++
+			}
+		}
+	}
+	return fmt.Errorf("%d of %d documents were rejected, %s", rejected, len(response.Items), reason)
+}
+
+// encode encodes the batch in the configured format, gzip compressed if configured.
+func (s *HTTPSink) encode(batch []sinkEntry) ([]byte, string, error) {
+	var body bytes.Buffer
+	contentType := "application/json"
+
+	switch s.config.Format {
+	case HTTPFormatLoki:
+		values := make([][2]string, len(batch))
+		for i, entry := range batch {
+			values[i] = [2]string{strconv.FormatInt(entry.time.UnixNano(), 10), string(entry.line)}
+		}
+		labels := s.config.Labels
+		if len(labels) == 0 {
+			labels = map[string]string{"job": "sen-go"}
+		}
+		push := map[string]interface{}{
+			"streams": []map[string]interface{}{{"stream": labels, "values": values}},
+		}
+		if err := json.NewEncoder(&body).Encode(push); err != nil {
+			return nil, "", err
+		}
+	case HTTPFormatElasticsearch:
+		contentType = "application/x-ndjson"
+		action, err := json.Marshal(map[string]interface{}{"index": map[string]string{"_index": s.config.Index}})
+		if err != nil {
+			return nil, "", err
+		}
+		for _, entry := range batch {
+			body.Write(action)
+			body.WriteByte('\n')
+			body.Write(sinkDocument(entry))
+			body.WriteByte('\n')
+		}
+	default:
+		body.WriteByte('[')
+		for i, entry := range batch {
+			if i > 0 {
+				body.WriteByte(',')
+			}
+			body.Write(sinkDocument(entry))
+		}
+		body.WriteByte(']')
+	}
+
+	if !s.config.Gzip {
+		return body.Bytes(), contentType, nil
+	}
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(body.Bytes()); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return compressed.Bytes(), contentType, nil
+}
+
+// sinkDocument returns the entry as a JSON document, wrapping lines that are not JSON objects.
+func sinkDocument(entry sinkEntry) []byte {
+	if entry.fields != nil {
+		return entry.line
+	}
+	document, _ := json.Marshal(map[string]string{
+		"level":   entry.level.CapitalString(),
+		"time":    entry.time.Format(time.RFC3339Nano),
+		"message": entry.message,
+	})
+	return document
+}
@@ -0,0 +1,274 @@
+package log
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPSinkBatches(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var batch []map[string]interface{}
+		if err := json.NewDecoder(reader).Decode(&batch); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		batches = append(batches, batch)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTPSink(HTTPSinkConfig{
+		URL:           server.URL,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Gzip:          true,
+		Headers:       map[string]string{"Authorization": "Bearer token"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := sink.Write([]byte(testEntry)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("unexpected batches %v", batches)
+	}
+	if batches[0][0]["message"] != "failed" {
+		t.Fatalf("unexpected entry %v", batches[0][0])
+	}
+}
+
+func TestHTTPSinkRetries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTPSink(HTTPSinkConfig{URL: server.URL, FlushInterval: time.Hour, MaxRetries: 2, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if _, err := sink.Write([]byte(testEntry)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests.Load())
+	}
+}
+
+func TestHTTPSinkDoesNotRetryClientErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTPSink(HTTPSinkConfig{URL: server.URL, FlushInterval: time.Hour, MaxRetries: 2, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if _, err := sink.Write([]byte(testEntry)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Sync(); err == nil {
+		t.Fatal("expected the post to fail")
+	}
+	if requests.Load() != 1 {
+		t.Fatalf("expected 1 request, got %d", requests.Load())
+	}
+}
+
+func TestHTTPSinkLoki(t *testing.T) {
+	bodies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTPSink(HTTPSinkConfig{URL: server.URL, Format: HTTPFormatLoki, Labels: map[string]string{"app": "test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if _, err := sink.Write([]byte(testEntry)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	body := <-bodies
+	if !strings.Contains(body, `"stream":{"app":"test"}`) || !strings.Contains(body, `"1709287200000000000"`) {
+		t.Fatalf("unexpected Loki push %s", body)
+	}
+}
+
+func TestHTTPSinkWriteAfterClose(t *testing.T) {
+	sink, err := NewHTTPSink(HTTPSinkConfig{URL: "http://127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sink.Write([]byte(testEntry)); err == nil {
+		t.Fatal("expected the write to fail once closed")
+	}
+}
+
+func TestHTTPSinkCloseDoesNotRetry(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTPSink(HTTPSinkConfig{URL: server.URL, FlushInterval: time.Hour, MaxRetries: 5, RetryBackoff: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sink.Write([]byte(testEntry)); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := sink.Close(); err == nil {
+		t.Fatal("expected the post to fail")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected Close to return without retrying, took %s", elapsed)
+	}
+	if requests.Load() != 1 {
+		t.Fatalf("expected 1 request, got %d", requests.Load())
+	}
+}
+
+func TestHTTPSinkSyncTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	sink, err := NewHTTPSink(HTTPSinkConfig{URL: server.URL, FlushInterval: time.Hour, FlushTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if _, err := sink.Write([]byte(testEntry)); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := sink.Sync(); err == nil {
+		t.Fatal("expected the post to time out")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected Sync to return after the flush timeout, took %s", elapsed)
+	}
+}
+
+func TestHTTPSinkElasticsearchErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		err      string
+	}{
+		{"accepted", `{"errors":false,"items":[{"index":{"status":201}}]}`, ""},
+		{"rejected", `{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}]}`, "1 of 2 documents were rejected, mapper_parsing_exception: failed to parse"},
+		{"invalid", `not json`, "invalid bulk response"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if !strings.Contains(string(body), `{"index":{"_index":"logs"}}`) {
+					t.Errorf("unexpected bulk request %s", body)
+				}
+				_, _ = io.WriteString(w, test.response)
+			}))
+			defer server.Close()
+
+			sink, err := NewHTTPSink(HTTPSinkConfig{URL: server.URL, Format: HTTPFormatElasticsearch, Index: "logs", FlushInterval: time.Hour})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sink.Close()
+
+			if _, err := sink.Write([]byte(testEntry + testEntry)); err != nil {
+				t.Fatal(err)
+			}
+			err = sink.Sync()
+			if test.err == "" && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+				t.Fatalf("expected the error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestHTTPSinkCountsDroppedEntries(t *testing.T) {
+	sink, err := NewHTTPSink(HTTPSinkConfig{URL: "http://127.0.0.1:0", BatchSize: 10, MaxPending: 2, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	before := Dropped()
+	if _, err := sink.Write([]byte(testEntry + testEntry + testEntry)); err != nil {
+		t.Fatal(err)
+	}
+	after := Dropped()
+	if after.HTTPSink-before.HTTPSink != 1 || after.Overflow != before.Overflow {
+		t.Fatalf("expected 1 entry dropped by the HTTP sink, got %+v then %+v", before, after)
+	}
+}
@@ -3,43 +3,157 @@ package log
 import (
 	"fmt"
 	"github.com/s3ndd/sen-go/config"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
-// sharedLogger holds the global Logger
+// sharedLogger holds the global Logger in a loggerHolder
 var sharedLogger atomic.Value
 
+// sharedMu serializes the creation and replacement of the global logger, so a single one is created
+var sharedMu sync.Mutex
+
+// loggerHolder holds a Logger, so loggers of different types can be stored in sharedLogger
+type loggerHolder struct {
+	logger Logger
+}
+
+// loadLogger returns the global logger or nil if it is not set
+func loadLogger() Logger {
+	if holder, ok := sharedLogger.Load().(loggerHolder); ok {
+		return holder.logger
+	}
+	return nil
+}
+
 // Global returns the global logger
 func defaultConfig() *Config {
 	return &Config{
-		LogLevel:  config.String("LOG_LEVEL", "INFO"),
-		LogFormat: config.String("LOG_FORMAT", "json"),
-		Program:   config.String("SOURCE_PROGRAM", "unknown"),
-		Env:       config.String("ENV", "dev"),
+		LogLevel:   config.String("LOG_LEVEL", "INFO"),
+		LogFormat:  config.String("LOG_FORMAT", "json"),
+		Program:    config.String("SOURCE_PROGRAM", "unknown"),
+		Env:        config.String("ENV", "dev"),
+		LogLevels:  config.String("LOG_LEVELS", ""),
+		SpanEvents: config.Bool("LOG_TRACE_SPAN_EVENTS", false),
+		File: FileConfig{
+			Path:           config.String("LOG_FILE_PATH", ""),
+			MaxSize:        config.Int("LOG_FILE_MAX_SIZE", 100),
+			RotateInterval: config.Duration("LOG_FILE_ROTATE_INTERVAL", 0),
+			MaxBackups:     config.Int("LOG_FILE_MAX_BACKUPS", 0),
+			MaxAge:         config.Duration("LOG_FILE_MAX_AGE", 0),
+			Compress:       config.Bool("LOG_FILE_COMPRESS", false),
+			ReopenOnSIGHUP: config.Bool("LOG_FILE_REOPEN_ON_SIGHUP", false),
+		},
+		Sampling: SamplingConfig{
+			Initial:      config.Int("LOG_SAMPLING_INITIAL", 0),
+			Thereafter:   config.Int("LOG_SAMPLING_THEREAFTER", 100),
+			Interval:     config.Duration("LOG_SAMPLING_INTERVAL", time.Second),
+			RateLimit:    config.Float("LOG_RATE_LIMIT", 0),
+			RateBurst:    config.Int("LOG_RATE_BURST", 10),
+			RateLimitKey: config.String("LOG_RATE_LIMIT_KEY", ""),
+			DedupWindow:  config.Duration("LOG_DEDUP_WINDOW", 0),
+		},
+		Redaction: RedactionConfig{
+			Enabled:  config.Bool("LOG_REDACT", false),
+			Keys:     config.Strings("LOG_REDACT_KEYS", nil),
+			Strategy: RedactStrategy(config.String("LOG_REDACT_STRATEGY", string(RedactMask))),
+		},
+		Async: AsyncConfig{
+			Enabled:       config.Bool("LOG_ASYNC", false),
+			BufferSize:    config.Int("LOG_ASYNC_BUFFER_SIZE", 4096),
+			FlushInterval: config.Duration("LOG_ASYNC_FLUSH_INTERVAL", 100*time.Millisecond),
+			Overflow:      OverflowPolicy(config.String("LOG_ASYNC_OVERFLOW", string(OverflowBlock))),
+		},
+		Syslog: SyslogConfig{
+			Network:  config.String("LOG_SYSLOG_NETWORK", "udp"),
+			Address:  config.String("LOG_SYSLOG_ADDRESS", ""),
+			Facility: SyslogFacility(config.Int("LOG_SYSLOG_FACILITY", int(FacilityUser))),
+			AppName:  config.String("LOG_SYSLOG_APP_NAME", ""),
+		},
+		Journald: JournaldConfig{
+			Enabled:    config.Bool("LOG_JOURNALD", false),
+			Socket:     config.String("LOG_JOURNALD_SOCKET", ""),
+			Identifier: config.String("LOG_JOURNALD_IDENTIFIER", ""),
+		},
+		HTTP: HTTPSinkConfig{
+			URL:           config.String("LOG_HTTP_URL", ""),
+			Format:        HTTPSinkFormat(config.String("LOG_HTTP_FORMAT", string(HTTPFormatJSON))),
+			BatchSize:     config.Int("LOG_HTTP_BATCH_SIZE", 100),
+			FlushInterval: config.Duration("LOG_HTTP_FLUSH_INTERVAL", time.Second),
+			MaxRetries:    config.Int("LOG_HTTP_MAX_RETRIES", 3),
+			FlushTimeout:  config.Duration("LOG_HTTP_FLUSH_TIMEOUT", 5*time.Second),
+			Gzip:          config.Bool("LOG_HTTP_GZIP", true),
+			Index:         config.String("LOG_HTTP_INDEX", ""),
+		},
 	}
 }
 
-// SetLogger sets the default global logger
+// SetLogger sets the default global logger.
+// Unlike the loggers created by Init, the logger does not follow GlobalLevel and SetNamedLevels.
 func SetLogger(logger Logger) error {
-	if sharedLogger.Load() != nil {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	if loadLogger() != nil {
 		return fmt.Errorf("Shared logger exists, cannot be modified")
 	}
-	sharedLogger.Store(logger)
+	sharedLogger.Store(loggerHolder{logger})
 	return nil
 }
 
-// SetGlobalFields sets fields on the log entry that should be global to all requests
+// ReplaceGlobal replaces the global logger, even if it is already set, and returns
+// a function restoring the previous one.
+func ReplaceGlobal(logger Logger) (restore func()) {
+	previous := loadLogger()
+	sharedLogger.Store(loggerHolder{logger})
+	return func() {
+		sharedLogger.Store(loggerHolder{previous})
+	}
+}
+
+// SetGlobalFields sets fields on the log entry that should be global to all requests.
+// The fields are added to the current global logger, even if it is already set.
 func SetGlobalFields(fields Fields) {
-	_ = SetLogger(Global().WithFields(fields))
+	sharedLogger.Store(loggerHolder{Global().WithFields(fields)})
 }
 
 // Global returns the global log entry.
+// It is created from the environment on first use unless Init or SetLogger was called before.
 // Use SetGlobalFields to configure this entry with global fields.
 // Use ForRequest if you want a log entry pre-configured with relevant request metadata
 func Global() Logger {
-	if sharedLogger.Load() == nil {
-		_ = SetLogger(NewLogger(defaultConfig()))
+	if logger := loadLogger(); logger != nil {
+		return logger
+	}
+
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	if logger := loadLogger(); logger != nil {
+		return logger
+	}
+	logger, err := newZapLogger(defaultConfig(), true)
+	if err != nil {
+		panic(err)
 	}
+	sharedLogger.Store(loggerHolder{logger})
+	return logger
+}
 
-	return sharedLogger.Load().(Logger)
+// defaultAuditConfig reads the audit logger configuration from the environment.
+func defaultAuditConfig() *AuditConfig {
+	return &AuditConfig{
+		File: FileConfig{
+			Path:       config.String("LOG_AUDIT_PATH", ""),
+			MaxSize:    config.Int("LOG_AUDIT_MAX_SIZE", 100),
+			MaxBackups: config.Int("LOG_AUDIT_MAX_BACKUPS", 0),
+			MaxAge:     config.Duration("LOG_AUDIT_MAX_AGE", 0),
+			Compress:   config.Bool("LOG_AUDIT_COMPRESS", false),
+		},
+		Stdout:          config.Bool("LOG_AUDIT_STDOUT", false),
+		StatePath:       config.String("LOG_AUDIT_STATE_PATH", ""),
+		Key:             config.String("LOG_AUDIT_KEY", ""),
+		SyncEveryRecord: config.Bool("LOG_AUDIT_SYNC", true),
+	}
 }
@@ -0,0 +1,172 @@
+//go:build !windows
+
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// journaldSocket is the default journald native protocol socket
+const journaldSocket = "/run/systemd/journal/socket"
+
+// JournaldSink is a zapcore.WriteSyncer sending entries to journald with the native protocol.
+// The fields of the entries are sent as journal fields, the encoded entry as JSON_ENTRY.
+type JournaldSink struct {
+	identifier string
+	addr       *net.UnixAddr
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// NewJournaldSink creates a new JournaldSink.
+func NewJournaldSink(config JournaldConfig) (*JournaldSink, error) {
+	socket := config.Socket
+	if socket == "" {
+		socket = journaldSocket
+	}
+	identifier := config.Identifier
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the journald socket, %w", err)
+	}
+	return &JournaldSink{
+		identifier: identifier,
+		addr:       &net.UnixAddr{Name: socket, Net: "unixgram"},
+		conn:       conn,
+	}, nil
+}
+
+// Write sends each encoded entry as a journal entry.
+func (s *JournaldSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return 0, errors.New("journald sink is closed")
+	}
+	for _, entry := range parseSinkEntries(p) {
+		if err := s.send(s.format(entry)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Sync is a no-op as entries are sent when written.
+func (s *JournaldSink) Sync() error {
+	return nil
+}
+
+// Close closes the socket.
+func (s *JournaldSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// format encodes the entry with the journald native protocol.
+func (s *JournaldSink) format(entry sinkEntry) []byte {
+	var message bytes.Buffer
+	writeJournaldField(&message, "MESSAGE", entry.message)
+	writeJournaldField(&message, "PRIORITY", fmt.Sprint(syslogSeverity(entry.level)))
+	writeJournaldField(&message, "SYSLOG_IDENTIFIER", s.identifier)
+	writeJournaldField(&message, "JSON_ENTRY", string(entry.line))
+
+	keys := make([]string, 0, len(entry.fields))
+	for key := range entry.fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch name := journaldFieldName(key); name {
+		case "", "MESSAGE", "LEVEL", "PRIORITY", "SYSLOG_IDENTIFIER", "JSON_ENTRY":
+		default:
+			writeJournaldField(&message, name, sinkFieldString(entry.fields[key]))
+		}
+	}
+	return message.Bytes()
+}
+
+// send sends the datagram, passing it in a file descriptor if it is too large for a datagram.
+func (s *JournaldSink) send(message []byte) error {
+	_, err := s.conn.WriteToUnix(message, s.addr)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return fmt.Errorf("failed to send the journald entry, %w", err)
+	}
+
+	file, err := os.CreateTemp("", "journald")
+	if err != nil {
+		return fmt.Errorf("failed to create the journald entry file, %w", err)
+	}
+	defer file.Close()
+	_ = os.Remove(file.Name())
+
+	if _, err := file.Write(message); err != nil {
+		return fmt.Errorf("failed to write the journald entry file, %w", err)
+	}
+	if _, _, err := s.conn.WriteMsgUnix(nil, syscall.UnixRights(int(file.Fd())), s.addr); err != nil {
+		return fmt.Errorf("failed to send the journald entry file, %w", err)
+	}
+	return nil
+}
+
+// writeJournaldField writes a field, with the binary encoding if the value spans several lines.
+func writeJournaldField(message *bytes.Buffer, name string, value string) {
+	message.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		message.WriteByte('=')
+		message.WriteString(value)
+		message.WriteByte('\n')
+		return
+	}
+
+	message.WriteByte('\n')
+	_ = binary.Write(message, binary.LittleEndian, uint64(len(value)))
+	message.WriteString(value)
+	message.WriteByte('\n')
+}
+
+// journaldFieldName returns the journal field name of a key: upper case letters, digits and
+// underscores, not starting with an underscore or a digit.
+func journaldFieldName(key string) string {
+	name := make([]byte, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case c >= 'a' && c <= 'z':
+			name = append(name, c-'a'+'A')
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			name = append(name, c)
+		default:
+			name = append(name, '_')
+		}
+	}
+	name = bytes.TrimLeft(name, "_0123456789")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return string(name)
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package log
+
+import "errors"
+
+// JournaldSink is not supported on windows, which has no journald.
+type JournaldSink struct{}
+
+// NewJournaldSink returns an error on windows, which has no journald.
+func NewJournaldSink(config JournaldConfig) (*JournaldSink, error) {
+	return nil, errors.New("journald is not supported on windows")
+}
+
+// Write is a no-op on windows.
+func (s *JournaldSink) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// Sync is a no-op on windows.
+func (s *JournaldSink) Sync() error {
+	return nil
+}
+
+// Close is a no-op on windows.
+func (s *JournaldSink) Close() error {
+	return nil
+}
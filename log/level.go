@@ -0,0 +1,188 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// globalLevel is the level of the global logger and the loggers derived from it
+var globalLevel = NewLevelController(zapcore.InfoLevel)
+
+// GlobalLevel returns the level controller of the global logger, created by Init or on first use,
+// and of the loggers derived from it.
+func GlobalLevel() *LevelController {
+	return globalLevel
+}
+
+// LevelController controls the level of a logger at runtime.
+type LevelController struct {
+	atomicLevel zap.AtomicLevel
+
+	mu         sync.Mutex
+	configured zapcore.Level
+	revertAt   time.Time
+	timer      *time.Timer
+}
+
+// NewLevelController creates a new LevelController starting at the configured level.
+func NewLevelController(configured zapcore.Level) *LevelController {
+	return &LevelController{
+		atomicLevel: zap.NewAtomicLevelAt(configured),
+		configured:  configured,
+	}
+}
+
+// AtomicLevel returns the zap.AtomicLevel controlled by the LevelController.
+func (c *LevelController) AtomicLevel() zap.AtomicLevel {
+	return c.atomicLevel
+}
+
+// Level returns the current level.
+func (c *LevelController) Level() Level {
+	return levelFromZap(c.atomicLevel.Level())
+}
+
+// Configured returns the level the controller reverts to.
+func (c *LevelController) Configured() Level {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return levelFromZap(c.configured)
+}
+
+// RevertAt returns the time the current level reverts to the configured level.
+// It returns the zero time if no revert is scheduled.
+func (c *LevelController) RevertAt() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.revertAt
+}
+
+// SetConfigured sets the configured level and resets the current level to it.
+func (c *LevelController) SetConfigured(level Level) error {
+	zapLevel, err := level.zapLevel()
+	if err != nil {
+		return err
+	}
+
+	c.configure(zapLevel)
+	return nil
+}
+
+// configure sets the configured level and resets the current level to it.
+func (c *LevelController) configure(level zapcore.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.configured = level
+	c.reset()
+}
+
+// SetLevel sets the current level.
+// If ttl is greater than zero, the level reverts to the configured level once it expires.
+func (c *LevelController) SetLevel(level Level, ttl time.Duration) error {
+	zapLevel, err := level.zapLevel()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopTimer()
+	c.atomicLevel.SetLevel(zapLevel)
+	if ttl > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(ttl, func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.timer == timer {
+				c.reset()
+			}
+		})
+		c.timer = timer
+		c.revertAt = time.Now().Add(ttl)
+	}
+	return nil
+}
+
+// Reset reverts the current level to the configured level.
+func (c *LevelController) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reset()
+}
+
+// reset reverts the current level to the configured level. The caller must hold the lock.
+func (c *LevelController) reset() {
+	c.stopTimer()
+	c.atomicLevel.SetLevel(c.configured)
+}
+
+// stopTimer cancels the scheduled revert. The caller must hold the lock.
+func (c *LevelController) stopTimer() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.revertAt = time.Time{}
+}
+
+// zapLevel converts the level to a zapcore.Level.
+func (l Level) zapLevel() (zapcore.Level, error) {
+	zapLevel := zapcore.Level(0)
+	if err := zapLevel.UnmarshalText([]byte(l)); err != nil {
+		return zapLevel, fmt.Errorf("invalid log level %q", l)
+	}
+	return zapLevel, nil
+}
+
+// levelFromZap converts a zapcore.Level to a Level.
+func levelFromZap(level zapcore.Level) Level {
+	return Level(level.CapitalString())
+}
+
+// levelCore is a zapcore.Core filtering entries by a level that can be replaced
+// without rebuilding the underlying core.
+type levelCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+}
+
+// newLevelCore returns a core filtering the entries of the core by the level.
+// If the core is already a levelCore, its level is replaced.
+func newLevelCore(core zapcore.Core, level zapcore.LevelEnabler) zapcore.Core {
+	if lc, ok := core.(*levelCore); ok {
+		core = lc.Core
+	}
+	return &levelCore{Core: core, level: level}
+}
+
+// Enabled returns true if the level is enabled.
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+// Level returns the minimum enabled level.
+func (c *levelCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.level)
+}
+
+// With adds fields to the underlying core.
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+// Check adds the core to the checked entry if the level is enabled.
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
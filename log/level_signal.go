@@ -0,0 +1,37 @@
+//go:build !windows
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// NotifySignals switches the level to debug on SIGUSR1 and back to the configured level on SIGUSR2.
+// It returns a function to stop listening for the signals.
+func (c *LevelController) NotifySignals() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig == syscall.SIGUSR1 {
+					_ = c.SetLevel(LevelDebug, 0)
+				} else {
+					c.Reset()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
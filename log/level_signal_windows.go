@@ -0,0 +1,8 @@
+//go:build windows
+
+package log
+
+// NotifySignals is a no-op on windows, which has no SIGUSR1 and SIGUSR2.
+func (c *LevelController) NotifySignals() (stop func()) {
+	return func() {}
+}
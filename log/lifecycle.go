@@ -0,0 +1,154 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Init creates the global logger from the config, replacing the current one and closing
+// its outputs. The loggers derived from the current one then write to the new global logger.
+// The config is read from the environment when nil.
+func Init(config *Config) error {
+	if config == nil {
+		config = defaultConfig()
+	}
+
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	logger, err := newZapLogger(config, true)
+	if err != nil {
+		return err
+	}
+
+	previous := loadLogger()
+	sharedLogger.Store(loggerHolder{logger})
+	if closer, ok := previous.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Sync flushes the entries buffered by the global logger to its outputs.
+func Sync() error {
+	var err error
+	if syncer, ok := loadLogger().(interface{ Sync() error }); ok {
+		err = syncer.Sync()
+	}
+	if audit := sharedAudit.Load(); audit != nil {
+		err = errors.Join(err, audit.Sync())
+	}
+	return err
+}
+
+// Close flushes the global logger, the global audit logger and the hooks and closes their
+// outputs. It is called on shutdown.
+func Close() error {
+	err := hooks.Close()
+	if audit := sharedAudit.Load(); audit != nil {
+		err = errors.Join(err, audit.Close())
+	}
+	if closer, ok := loadLogger().(io.Closer); ok {
+		return errors.Join(err, closer.Close())
+	}
+	return errors.Join(err, Sync())
+}
+
+// Sync flushes the entries buffered by the logger to its outputs.
+func (l *ZapLogger) Sync() error {
+	return l.Logger.Sync()
+}
+
+// Close flushes the logger and closes its outputs.
+// The loggers derived from the logger share its outputs and are closed too.
+func (l *ZapLogger) Close() error {
+	return errors.Join(l.Sync(), l.sinks.Close())
+}
+
+// syncer is an output flushed on Sync and closed on Close if it is an io.Closer.
+type syncer interface {
+	Sync() error
+}
+
+// sinkSet holds the outputs of a logger and the loggers derived from it.
+type sinkSet struct {
+	mu     sync.Mutex
+	sinks  []syncer
+	closed atomic.Bool
+}
+
+// add registers an output.
+func (s *sinkSet) add(sink syncer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sinks = append(s.sinks, sink)
+}
+
+// Close flushes and closes the outputs in the reverse order they were added,
+// so buffers are flushed before the outputs they write to are closed.
+func (s *sinkSet) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed.Store(true)
+	var errs []error
+	for i := len(s.sinks) - 1; i >= 0; i-- {
+		sink := s.sinks[i]
+		errs = append(errs, sink.Sync())
+		if closer, ok := sink.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sharedCore is the core of the global logger the closed shared loggers forward their entries to
+var sharedCore atomic.Pointer[forwardCore]
+
+// forwardCore is the core of a shared logger. Once its outputs are closed, e.g. by Init replacing
+// the logger, it forwards the entries to the core of the current global logger, so the loggers
+// derived from it before the replacement do not lose their entries.
+type forwardCore struct {
+	zapcore.Core
+	sinks  *sinkSet
+	fields []zapcore.Field
+}
+
+// With adds fields to the core, and keeps them to add to the forwarded entries.
+func (c *forwardCore) With(fields []zapcore.Field) zapcore.Core {
+	return &forwardCore{
+		Core:   c.Core.With(fields),
+		sinks:  c.sinks,
+		fields: append(append([]zapcore.Field{}, c.fields...), fields...),
+	}
+}
+
+// Check adds the core to the checked entry, or the core of the global logger once the outputs are closed.
+func (c *forwardCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.sinks.closed.Load() {
+		if target := sharedCore.Load(); target != nil && target.sinks != c.sinks && !target.sinks.closed.Load() {
+			return target.Core.With(c.fields).Check(entry, checked)
+		}
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// stdoutSink writes to stdout, ignoring the errors returned when syncing a terminal or pipe.
+type stdoutSink struct {
+	zapcore.WriteSyncer
+}
+
+// Sync flushes stdout.
+func (s stdoutSink) Sync() error {
+	err := s.WriteSyncer.Sync()
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTSUP) || errors.Is(err, syscall.ENOTTY) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// resetGlobal clears the global logger and closes the one created by the test on cleanup.
+func resetGlobal(t *testing.T) {
+	previous := loadLogger()
+	sharedLogger.Store(loggerHolder{})
+	t.Cleanup(func() {
+		_ = Close()
+		sharedLogger.Store(loggerHolder{previous})
+	})
+}
+
+func TestGlobalIsCreatedOnce(t *testing.T) {
+	resetGlobal(t)
+
+	loggers := make([]Logger, 10)
+	var wg sync.WaitGroup
+	for i := range loggers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			loggers[i] = Global()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, logger := range loggers {
+		if logger != loggers[0] {
+			t.Fatal("expected a single global logger")
+		}
+	}
+}
+
+func TestInitForwardsTheEntriesOfThePreviousLogger(t *testing.T) {
+	resetGlobal(t)
+
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+	if err := Init(&Config{LogLevel: "info", LogFormat: "json", Program: "app", File: FileConfig{Path: first}}); err != nil {
+		t.Fatal(err)
+	}
+	derived := Global().WithField("order", 1)
+
+	if err := Init(&Config{LogLevel: "info", LogFormat: "json", Program: "app", File: FileConfig{Path: second}}); err != nil {
+		t.Fatal(err)
+	}
+	derived.Info("created")
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := string(content)
+	if !strings.Contains(line, `"message":"created"`) || !strings.Contains(line, `"order":1`) {
+		t.Fatalf("expected the entry in the new output, got %q", line)
+	}
+	if strings.Count(line, "source_program") != 1 {
+		t.Fatalf("expected a single source_program field, got %q", line)
+	}
+}
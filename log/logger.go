@@ -15,57 +15,243 @@ const RequestIDHeaderName = "X-Request-Id"
 // ZapLogger is a wrapper around the zap logger
 type ZapLogger struct {
 	config *zap.Config
+	name   string
+	sinks  *sinkSet
+	scope  *loggerScope
 	*zap.Logger
 }
 
-// NewZapLogger creates a new zap logger
+// loggerScope holds the settings shared by a logger and the loggers derived from it.
+type loggerScope struct {
+	// levels are the levels of the named loggers, shared by all the loggers for the global logger
+	levels *levelRegistry
+	// spanEvents records the entries of the ForRequest loggers as span events
+	spanEvents bool
+}
+
+// NewZapLogger creates a new zap logger with its own level and named levels.
+// Only the global logger, created by Init or on first use, follows GlobalLevel and SetNamedLevels.
+// It panics if the config is invalid, use Init to get an error instead.
 func NewZapLogger(config *Config) Logger {
-	zapConfig := newZapConfig(config)
-	zapCore := zapcore.NewCore(
-		zapcore.NewJSONEncoder(zapConfig.EncoderConfig),
-		zapcore.Lock(os.Stdout),
-		zapConfig.Level,
-	)
+	logger, err := newZapLogger(config, false)
+	if err != nil {
+		panic(err)
+	}
+	return logger
+}
+
+// newZapLogger creates a new zap logger, returning an error if the config is invalid.
+// A shared logger configures and follows the global level and named levels.
+func newZapLogger(config *Config, shared bool) (*ZapLogger, error) {
+	zapConfig, err := newZapConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	namedLevelRules, err := parseNamedLevels(config.NamedLogLevels())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to set the named log levels from config. %s", err)
+	}
+
+	sinks := &sinkSet{}
+	core, err := newCore(config, zapConfig, sinks)
+	if err != nil {
+		_ = sinks.Close()
+		return nil, err
+	}
+
+	scope := &loggerScope{levels: newLevelRegistry(), spanEvents: config.TraceSpanEvents()}
+	if shared {
+		globalLevel.configure(zapConfig.Level.Level())
+		zapConfig.Level = globalLevel.AtomicLevel()
+		scope.levels = namedLevels
+	}
+	if len(namedLevelRules) > 0 {
+		scope.levels.replace(namedLevelRules)
+	}
+
+	if shared {
+		forward := &forwardCore{Core: core, sinks: sinks}
+		sharedCore.Store(forward)
+		core = forward
+	}
+	zapCore := newLevelCore(core, zapConfig.Level)
 
-	logger := zap.New(zapCore, zap.AddCaller(), zap.AddStacktrace(zap.ErrorLevel))
+	logger := zap.New(zapCore, zap.AddCaller(), zap.AddCallerSkip(1), zap.AddStacktrace(zap.ErrorLevel))
 
 	// attach the source name to the logger
 	logger = logger.With(zap.String("source_program", config.SourceProgram()))
 
 	zapLogger := &ZapLogger{
 		zapConfig,
+		"",
+		sinks,
+		scope,
 		logger,
 	}
 
-	return zapLogger
+	return zapLogger, nil
 }
 
-// NewContext updates the logger stored in the context by adding new zap.Fields to it
-func NewContext(ctx context.Context, fields ...zap.Field) context.Context {
+// NewContext updates the logger stored in the context by adding new fields to it
+func NewContext(ctx context.Context, fields ...Field) context.Context {
 	return ContextWithLogger(ctx, WithContext(ctx).With(fields...))
 }
 
 // WithContext returns the logger in the context or the global logger
-func WithContext(ctx context.Context) *ZapLogger {
-	logger := ContextLogger(ctx)
-	if logger == nil {
-		logger = Global()
-	}
-	return logger.(*ZapLogger)
+func WithContext(ctx context.Context) Logger {
+	return ContextLogger(ctx)
 }
 
 // ForRequest returns a Logger for the request context.
+// The logger carries the request_id and the baggage fields of the context and the trace_id and
+// span_id of the trace in the context, if any. The fields already carried by the logger stored
+// with ContextForRequest are not added again.
 func ForRequest(ctx context.Context) Logger {
-	logger := ContextLogger(ctx)
-	logger = logger.WithField("request_id", ctx.Value(RequestIDHeaderName))
+	logger, _ := forRequest(ctx)
 	return logger
 }
 
+// ContextForRequest returns a new context storing the logger returned by ForRequest, so that
+// ContextLogger returns it and ForRequest does not add its fields again.
+func ContextForRequest(ctx context.Context) context.Context {
+	logger, fields := forRequest(ctx)
+	ctx = context.WithValue(ctx, requestFieldsContextKey, fields)
+	return ContextWithLogger(ctx, logger)
+}
+
+// RequestFieldsFromContext returns the request fields carried by the logger stored with
+// ContextForRequest, or stored under RequestFieldsKey in key-value stores such as gin.Context.
+func RequestFieldsFromContext(ctx context.Context) Fields {
+	if fields, ok := ctx.Value(requestFieldsContextKey).(Fields); ok {
+		return fields
+	}
+	fields, _ := ctx.Value(RequestFieldsKey).(Fields)
+	return fields
+}
+
+// forRequest returns the logger for the request context and the request fields it carries.
+func forRequest(ctx context.Context) (Logger, Fields) {
+	logger := ContextLogger(ctx)
+
+	carried := requestFields(ctx)
+	fields, traceApplied := unappliedRequestFields(ctx, carried)
+	if len(fields) > 0 {
+		logger = logger.WithFields(fields)
+	}
+
+	if traceID, spanID, ok := TraceContext(ctx); ok {
+		if !traceApplied {
+			logger = withTrace(ctx, logger)
+		}
+		carried[TraceIDKey], carried[SpanIDKey] = traceID, spanID
+	}
+	return logger, carried
+}
+
+// unappliedRequestFields returns the request fields not carried yet by the logger stored with
+// ContextForRequest, and true if it carries the trace of the context.
+func unappliedRequestFields(ctx context.Context, fields Fields) (Fields, bool) {
+	applied := RequestFieldsFromContext(ctx)
+
+	unapplied := make(Fields, len(fields))
+	for key, value := range fields {
+		if appliedValue, ok := applied[key]; !ok || appliedValue != value {
+			unapplied[key] = value
+		}
+	}
+
+	traceID, spanID, ok := TraceContext(ctx)
+	return unapplied, ok && applied[TraceIDKey] == traceID && applied[SpanIDKey] == spanID
+}
+
+// requestFields returns the baggage fields and the request id of the context.
+func requestFields(ctx context.Context) Fields {
+	fields := Fields{}
+	for key, value := range BaggageFromContext(ctx) {
+		fields[key] = value
+	}
+	delete(fields, RequestIDKey)
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields[RequestIDKey] = requestID
+	}
+	return fields
+}
+
+// newCore creates the core writing the entries of the logger and registers its outputs.
+// Entries are redacted first, then sampled, then written to the output, asynchronously if
+// configured, to the remote sinks, always asynchronously, to the span and to the hooks.
+func newCore(config *Config, zapConfig *zap.Config, sinks *sinkSet) (zapcore.Core, error) {
+	writeSyncer, err := newWriteSyncer(config)
+	if err != nil {
+		return nil, err
+	}
+	sinks.add(writeSyncer)
+
+	encoder := zapcore.NewJSONEncoder(zapConfig.EncoderConfig)
+	core := zapcore.NewCore(encoder, writeSyncer, zapcore.DebugLevel)
+	if config.AsyncPolicy().Enabled {
+		writer, err := newAsyncWriter(writeSyncer, config.AsyncPolicy())
+		if err != nil {
+			return nil, err
+		}
+		sinks.add(writer)
+		core = newAsyncCore(encoder, writer, zapcore.DebugLevel)
+	}
+
+	remoteSinks, err := newRemoteSinks(config)
+	if err != nil {
+		return nil, err
+	}
+	if len(remoteSinks) > 0 {
+		for _, sink := range remoteSinks {
+			sinks.add(sink)
+		}
+		// a slow or unreachable server must never block the caller, whatever the async config
+		writer, err := newAsyncWriter(zapcore.NewMultiWriteSyncer(remoteSinks...), AsyncConfig{
+			BufferSize:    config.AsyncPolicy().BufferSize,
+			FlushInterval: config.AsyncPolicy().FlushInterval,
+			Overflow:      OverflowDropDebugFirst,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sinks.add(writer)
+		core = zapcore.NewTee(core, newAsyncCore(encoder.Clone(), writer, zapcore.DebugLevel))
+	}
+	// count the entries kept by sampling, rate limiting and duplicate suppression for the log metrics
+	core = zapcore.RegisterHooks(core, countEntry)
+	core = zapcore.NewTee(core, &spanEventCore{}, &hookCore{})
+	core = newSamplingCore(core, config.SamplingPolicy())
+
+	if config.RedactionPolicy().Enabled {
+		redactor, err := NewRedactor(config.RedactionPolicy())
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create the log redactor. %s", err)
+		}
+		core = &redactCore{Core: core, redactor: redactor}
+	}
+	return core, nil
+}
+
+// newWriteSyncer creates the output for the logger.
+// Logs are written to the rotating file sink when it is configured, otherwise to stdout.
+func newWriteSyncer(config *Config) (zapcore.WriteSyncer, error) {
+	if !config.FileSink().Enabled() {
+		return stdoutSink{zapcore.Lock(os.Stdout)}, nil
+	}
+
+	sink, err := NewFileSink(config.FileSink())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the log file sink. %s", err)
+	}
+	return sink, nil
+}
+
 // newZapConfig creates a new zap config
-func newZapConfig(config *Config) *zap.Config {
+func newZapConfig(config *Config) (*zap.Config, error) {
 	logLevel := zapcore.Level(0)
 	if err := logLevel.UnmarshalText([]byte(config.Level())); err != nil {
-		panic(fmt.Errorf("Failed to set the zap log level from config. %s", config.Level()))
+		return nil, fmt.Errorf("Failed to set the zap log level from config. %s", config.Level())
 	}
 
 	return &zap.Config{
@@ -85,31 +271,58 @@ func newZapConfig(config *Config) *zap.Config {
 			EncodeCaller: zapcore.ShortCallerEncoder,
 			EncodeTime:   zapcore.ISO8601TimeEncoder,
 		},
-	}
+	}, nil
 }
 
 // WithLevel returns the logger at the supplied level.
+// The returned logger writes to the same outputs but no longer follows the shared level.
 func (l *ZapLogger) WithLevel(level Level) Logger {
-	logLevel := zapcore.Level(0)
-	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+	logLevel, err := level.zapLevel()
+	if err != nil {
 		panic(fmt.Errorf("Failed to set the zap log level from config. %s", level))
 	}
 
+	config := *l.config
+	config.Level = zap.NewAtomicLevelAt(logLevel)
 	newLogger := l.Logger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
-		return zapcore.NewCore(
-			zapcore.NewJSONEncoder(l.config.EncoderConfig),
-			zapcore.Lock(os.Stdout),
-			logLevel,
-		)
+		return newLevelCore(c, config.Level)
+	}))
+
+	return &ZapLogger{&config, l.name, l.sinks, l.scope, newLogger}
+}
+
+// AtomicLevel returns the level of the logger.
+func (l *ZapLogger) AtomicLevel() zap.AtomicLevel {
+	return l.config.Level
+}
+
+// Named returns the logger with the supplied name appended to its name.
+// The level configured for the full name applies, falling back to the level of the logger.
+func (l *ZapLogger) Named(name string) Logger {
+	if name == "" {
+		return l
+	}
+
+	fullName := name
+	if l.name != "" {
+		fullName = l.name + "." + name
+	}
+
+	newLogger := l.Logger.Named(name).WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		fallback := zapcore.LevelEnabler(l.config.Level)
+		if lc, ok := c.(*levelCore); ok {
+			fallback = lc.level
+		}
+		return newLevelCore(c, l.scope.levels.enabler(fullName, fallback))
 	}))
 
-	return &ZapLogger{l.config, newLogger}
+	return &ZapLogger{l.config, fullName, l.sinks, l.scope, newLogger}
 }
 
 // WithField returns the logger at the supplied field.
 func (l *ZapLogger) WithField(key string, value interface{}) Logger {
 	newLogger := l.Logger.WithOptions(zap.Fields(zap.Any(key, value)))
-	return &ZapLogger{l.config, newLogger}
+	return &ZapLogger{l.config, l.name, l.sinks, l.scope, newLogger}
 }
 
 // WithFields returns the logger at the supplied fields.
@@ -119,17 +332,93 @@ func (l *ZapLogger) WithFields(fields Fields) Logger {
 		zapFields = append(zapFields, zap.Any(k, v))
 	}
 	newLogger := l.Logger.WithOptions(zap.Fields(zapFields...))
-	return &ZapLogger{l.config, newLogger}
+	return &ZapLogger{l.config, l.name, l.sinks, l.scope, newLogger}
 }
 
 // With returns the logger at the supplied fields.
-func (l *ZapLogger) With(fields ...zap.Field) Logger {
-	newLogger := l.Logger.With(fields...)
-	return &ZapLogger{l.config, newLogger}
+func (l *ZapLogger) With(fields ...Field) Logger {
+	newLogger := l.Logger.With(zapFields(fields)...)
+	return &ZapLogger{l.config, l.name, l.sinks, l.scope, newLogger}
 }
 
 // WithError returns the logger with the supplied error.
+// The error is logged with its causes, stack trace and attributes, see ErrorFields.
 func (l *ZapLogger) WithError(err error) Logger {
-	newLogger := l.Logger.WithOptions(zap.Fields(zap.Error(err)))
-	return &ZapLogger{l.config, newLogger}
+	newLogger := l.Logger.With(zapFields(ErrorFields("error", err))...)
+	return &ZapLogger{l.config, l.name, l.sinks, l.scope, newLogger}
+}
+
+// Enabled returns true if the logger writes entries at the level, so expensive
+// messages and fields can be skipped when it does not.
+func (l *ZapLogger) Enabled(level Level) bool {
+	zapLevel, err := level.zapLevel()
+	return err == nil && l.Logger.Core().Enabled(zapLevel)
+}
+
+// Debug logs a message at debug level.
+func (l *ZapLogger) Debug(message string, fields ...Field) {
+	l.Logger.Debug(message, zapFields(fields)...)
+}
+
+// Info logs a message at info level.
+func (l *ZapLogger) Info(message string, fields ...Field) {
+	l.Logger.Info(message, zapFields(fields)...)
+}
+
+// Warn logs a message at warn level.
+func (l *ZapLogger) Warn(message string, fields ...Field) {
+	l.Logger.Warn(message, zapFields(fields)...)
+}
+
+// Error logs a message at error level.
+func (l *ZapLogger) Error(message string, fields ...Field) {
+	l.Logger.Error(message, zapFields(fields)...)
+}
+
+// Fatal logs a message at fatal level and exits the process.
+func (l *ZapLogger) Fatal(message string, fields ...Field) {
+	l.Logger.Fatal(message, zapFields(fields)...)
+}
+
+// Panic logs a message at panic level and panics.
+func (l *ZapLogger) Panic(message string, fields ...Field) {
+	l.Logger.Panic(message, zapFields(fields)...)
+}
+
+// Debugf formats and logs a message at debug level, formatting only if the level is enabled.
+func (l *ZapLogger) Debugf(format string, args ...interface{}) {
+	if l.Logger.Core().Enabled(zapcore.DebugLevel) {
+		l.Logger.Debug(fmt.Sprintf(format, args...))
+	}
+}
+
+// Infof formats and logs a message at info level, formatting only if the level is enabled.
+func (l *ZapLogger) Infof(format string, args ...interface{}) {
+	if l.Logger.Core().Enabled(zapcore.InfoLevel) {
+		l.Logger.Info(fmt.Sprintf(format, args...))
+	}
+}
+
+// Warnf formats and logs a message at warn level, formatting only if the level is enabled.
+func (l *ZapLogger) Warnf(format string, args ...interface{}) {
+	if l.Logger.Core().Enabled(zapcore.WarnLevel) {
+		l.Logger.Warn(fmt.Sprintf(format, args...))
+	}
+}
+
+// Errorf formats and logs a message at error level, formatting only if the level is enabled.
+func (l *ZapLogger) Errorf(format string, args ...interface{}) {
+	if l.Logger.Core().Enabled(zapcore.ErrorLevel) {
+		l.Logger.Error(fmt.Sprintf(format, args...))
+	}
+}
+
+// Fatalf formats and logs a message at fatal level and exits the process.
+func (l *ZapLogger) Fatalf(format string, args ...interface{}) {
+	l.Logger.Fatal(fmt.Sprintf(format, args...))
+}
+
+// Panicf formats and logs a message at panic level and panics.
+func (l *ZapLogger) Panicf(format string, args ...interface{}) {
+	l.Logger.Panic(fmt.Sprintf(format, args...))
 }
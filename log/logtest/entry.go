@@ -0,0 +1,145 @@
+package logtest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s3ndd/sen-go/log"
+)
+
+// Entry is a recorded log entry.
+type Entry struct {
+	Level      log.Level
+	Message    string
+	LoggerName string
+	Caller     string
+	Time       time.Time
+	Fields     []log.Field
+}
+
+// Field returns the value of the last field with the key.
+func (e Entry) Field(key string) (interface{}, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == key {
+			return e.Fields[i].Resolved(), true
+		}
+	}
+	return nil, false
+}
+
+// FieldMap returns the fields of the entry by key.
+func (e Entry) FieldMap() map[string]interface{} {
+	fields := make(map[string]interface{}, len(e.Fields))
+	for _, field := range e.Fields {
+		fields[field.Key] = field.Resolved()
+	}
+	return fields
+}
+
+// HasField returns true if the entry has the field with the value.
+// Errors match their own value or their message.
+func (e Entry) HasField(key string, value interface{}) bool {
+	actual, ok := e.Field(key)
+	if !ok {
+		return false
+	}
+	if reflect.DeepEqual(actual, value) {
+		return true
+	}
+	if err, isErr := actual.(error); isErr {
+		if message, isString := value.(string); isString {
+			return err.Error() == message
+		}
+	}
+	return false
+}
+
+// String returns a readable representation of the entry.
+func (e Entry) String() string {
+	return fmt.Sprintf("%s %q %v", e.Level, e.Message, e.FieldMap())
+}
+
+// Entries is a list of recorded entries.
+type Entries []Entry
+
+// FilterMessage returns the entries with the message.
+func (e Entries) FilterMessage(message string) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return entry.Message == message
+	})
+}
+
+// FilterMessageContains returns the entries whose message contains the substring.
+func (e Entries) FilterMessageContains(substring string) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return strings.Contains(entry.Message, substring)
+	})
+}
+
+// FilterField returns the entries with the field.
+func (e Entries) FilterField(key string, value interface{}) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return entry.HasField(key, value)
+	})
+}
+
+// FilterFieldKey returns the entries with a field with the key, whatever its value.
+func (e Entries) FilterFieldKey(key string) Entries {
+	return e.Filter(func(entry Entry) bool {
+		_, ok := entry.Field(key)
+		return ok
+	})
+}
+
+// FilterLevel returns the entries at the level.
+func (e Entries) FilterLevel(level log.Level) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return entry.Level == level
+	})
+}
+
+// FilterLoggerName returns the entries of the named logger.
+func (e Entries) FilterLoggerName(name string) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return entry.LoggerName == name
+	})
+}
+
+// Filter returns the entries matching the predicate.
+func (e Entries) Filter(predicate func(Entry) bool) Entries {
+	var filtered Entries
+	for _, entry := range e {
+		if predicate(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// Len returns the number of entries.
+func (e Entries) Len() int {
+	return len(e)
+}
+
+// RequireLogged fails the test unless an entry has the level, message and fields.
+func (e Entries) RequireLogged(t testing.TB, level log.Level, message string, fields ...log.Field) {
+	t.Helper()
+
+	matched := e.FilterLevel(level).FilterMessage(message)
+	for _, field := range fields {
+		matched = matched.FilterField(field.Key, field.Resolved())
+	}
+	if len(matched) > 0 {
+		return
+	}
+
+	var logged strings.Builder
+	for _, entry := range e {
+		logged.WriteString("\n\t")
+		logged.WriteString(entry.String())
+	}
+	t.Fatalf("expected an entry %s %q with fields %v, logged:%s", level, message, fields, logged.String())
+}
@@ -0,0 +1,247 @@
+// Package logtest provides a Logger recording entries in memory, so tests can assert
+// what was logged.
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/s3ndd/sen-go/log"
+)
+
+// levelOrder orders the levels from the least to the most severe
+var levelOrder = map[log.Level]int{
+	log.LevelDebug: 0,
+	log.LevelInfo:  1,
+	log.LevelWarn:  2,
+	log.LevelError: 3,
+	log.LevelFatal: 4,
+	log.LevelPanic: 5,
+}
+
+// recorder holds the entries shared by a Logger and the loggers derived from it.
+type recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Logger is a log.Logger recording entries in memory.
+// Fatal records the entry without exiting the process. Panic records the entry and panics.
+type Logger struct {
+	recorder *recorder
+	level    log.Level
+	name     string
+	fields   []log.Field
+}
+
+// New creates a new Logger recording entries at every level.
+func New() *Logger {
+	return &Logger{recorder: &recorder{}, level: log.LevelDebug}
+}
+
+// Install creates a new Logger and installs it as the global logger until the test ends.
+func Install(t testing.TB) *Logger {
+	t.Helper()
+
+	logger := New()
+	t.Cleanup(log.ReplaceGlobal(logger))
+	return logger
+}
+
+// Context returns a new context with the logger as its context logger.
+func (l *Logger) Context(ctx context.Context) context.Context {
+	return log.ContextWithLogger(ctx, l)
+}
+
+// Entries returns the entries recorded by the logger and the loggers derived from it.
+func (l *Logger) Entries() Entries {
+	l.recorder.mu.Lock()
+	defer l.recorder.mu.Unlock()
+
+	return append(Entries{}, l.recorder.entries...)
+}
+
+// Reset removes the recorded entries.
+func (l *Logger) Reset() {
+	l.recorder.mu.Lock()
+	defer l.recorder.mu.Unlock()
+
+	l.recorder.entries = nil
+}
+
+// FilterMessage returns the entries with the message.
+func (l *Logger) FilterMessage(message string) Entries {
+	return l.Entries().FilterMessage(message)
+}
+
+// FilterField returns the entries with the field.
+func (l *Logger) FilterField(key string, value interface{}) Entries {
+	return l.Entries().FilterField(key, value)
+}
+
+// FilterLevel returns the entries at the level.
+func (l *Logger) FilterLevel(level log.Level) Entries {
+	return l.Entries().FilterLevel(level)
+}
+
+// RequireLogged fails the test unless an entry with the level, message and fields was recorded.
+func (l *Logger) RequireLogged(t testing.TB, level log.Level, message string, fields ...log.Field) {
+	t.Helper()
+	l.Entries().RequireLogged(t, level, message, fields...)
+}
+
+// WithLevel returns the logger recording entries at the level and above.
+func (l *Logger) WithLevel(level log.Level) log.Logger {
+	if _, ok := levelOrder[level]; !ok {
+		panic(fmt.Errorf("invalid log level %q", level))
+	}
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// WithField returns the logger with the field.
+func (l *Logger) WithField(key string, value interface{}) log.Logger {
+	return l.With(log.Any(key, value))
+}
+
+// WithFields returns the logger with the fields.
+func (l *Logger) WithFields(fields log.Fields) log.Logger {
+	list := make([]log.Field, 0, len(fields))
+	for key, value := range fields {
+		list = append(list, log.Any(key, value))
+	}
+	return l.With(list...)
+}
+
+// WithError returns the logger with the error.
+func (l *Logger) WithError(err error) log.Logger {
+	return l.With(log.Err(err))
+}
+
+// With returns the logger with the fields.
+func (l *Logger) With(fields ...log.Field) log.Logger {
+	clone := *l
+	clone.fields = append(append([]log.Field{}, l.fields...), fields...)
+	return &clone
+}
+
+// Named returns the logger with the name appended to its name.
+func (l *Logger) Named(name string) log.Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	clone.name = name
+	return &clone
+}
+
+// Enabled returns true if the logger records entries at the level.
+func (l *Logger) Enabled(level log.Level) bool {
+	order, ok := levelOrder[level]
+	return ok && order >= levelOrder[l.level]
+}
+
+// Debug records a message at debug level.
+func (l *Logger) Debug(message string, fields ...log.Field) {
+	l.record(log.LevelDebug, message, fields)
+}
+
+// Info records a message at info level.
+func (l *Logger) Info(message string, fields ...log.Field) {
+	l.record(log.LevelInfo, message, fields)
+}
+
+// Warn records a message at warn level.
+func (l *Logger) Warn(message string, fields ...log.Field) {
+	l.record(log.LevelWarn, message, fields)
+}
+
+// Error records a message at error level.
+func (l *Logger) Error(message string, fields ...log.Field) {
+	l.record(log.LevelError, message, fields)
+}
+
+// Fatal records a message at fatal level. It does not exit the process.
+func (l *Logger) Fatal(message string, fields ...log.Field) {
+	l.record(log.LevelFatal, message, fields)
+}
+
+// Panic records a message at panic level and panics.
+func (l *Logger) Panic(message string, fields ...log.Field) {
+	l.record(log.LevelPanic, message, fields)
+	panic(message)
+}
+
+// Debugf records a formatted message at debug level.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	if l.Enabled(log.LevelDebug) {
+		l.record(log.LevelDebug, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Infof records a formatted message at info level.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	if l.Enabled(log.LevelInfo) {
+		l.record(log.LevelInfo, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Warnf records a formatted message at warn level.
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	if l.Enabled(log.LevelWarn) {
+		l.record(log.LevelWarn, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Errorf records a formatted message at error level.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	if l.Enabled(log.LevelError) {
+		l.record(log.LevelError, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Fatalf records a formatted message at fatal level. It does not exit the process.
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	if l.Enabled(log.LevelFatal) {
+		l.record(log.LevelFatal, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Panicf records a formatted message at panic level and panics.
+func (l *Logger) Panicf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	l.record(log.LevelPanic, message, nil)
+	panic(message)
+}
+
+// record records an entry if the level is enabled.
+func (l *Logger) record(level log.Level, message string, fields []log.Field) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	entry := Entry{
+		Level:      level,
+		Message:    message,
+		LoggerName: l.name,
+		Time:       time.Now(),
+		Fields:     append(append([]log.Field{}, l.fields...), fields...),
+	}
+	if _, file, line, ok := runtime.Caller(2); ok {
+		entry.Caller = fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(file)), filepath.Base(file), line)
+	}
+
+	l.recorder.mu.Lock()
+	defer l.recorder.mu.Unlock()
+
+	l.recorder.entries = append(l.recorder.entries, entry)
+}
@@ -0,0 +1,148 @@
+package logtest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/s3ndd/sen-go/log"
+)
+
+// fatalRecorder is a testing.TB recording the failures instead of stopping the test.
+type fatalRecorder struct {
+	testing.TB
+	failure string
+}
+
+func (r *fatalRecorder) Helper() {}
+
+func (r *fatalRecorder) Fatalf(format string, args ...interface{}) {
+	r.failure = format
+}
+
+func TestLoggerRecords(t *testing.T) {
+	logger := New()
+	requestLogger := logger.WithField("request_id", "abc").Named("api")
+	requestLogger.Info("created", log.Int("order", 1))
+	requestLogger.Named("db").WithError(errors.New("timeout")).Error("failed")
+	logger.Debugf("loaded %d orders", 2)
+
+	entries := logger.Entries()
+	if entries.Len() != 3 {
+		t.Fatalf("expected 3 entries, got %v", entries)
+	}
+
+	created := entries[0]
+	if created.Level != log.LevelInfo || created.LoggerName != "api" || !strings.HasPrefix(created.Caller, "logtest/logger_test.go:") {
+		t.Fatalf("unexpected entry %+v", created)
+	}
+	if fields := created.FieldMap(); fields["request_id"] != "abc" || fields["order"] != 1 {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+	if entries[1].LoggerName != "api.db" || !entries[1].HasField("error", "timeout") {
+		t.Fatalf("unexpected entry %+v", entries[1])
+	}
+	if entries[2].Message != "loaded 2 orders" {
+		t.Fatalf("unexpected entry %+v", entries[2])
+	}
+
+	logger.Reset()
+	if logger.Entries().Len() != 0 {
+		t.Fatal("expected no entries after a reset")
+	}
+}
+
+func TestEntriesFilter(t *testing.T) {
+	logger := New()
+	logger.Info("created", log.String("tenant", "acme"))
+	logger.Named("api").Warn("created slowly", log.String("tenant", "globex"))
+	logger.Error("failed", log.Err(errors.New("timeout")))
+
+	tests := []struct {
+		name     string
+		entries  Entries
+		expected int
+	}{
+		{"message", logger.FilterMessage("created"), 1},
+		{"message contains", logger.Entries().FilterMessageContains("created"), 2},
+		{"field", logger.FilterField("tenant", "globex"), 1},
+		{"field key", logger.Entries().FilterFieldKey("tenant"), 2},
+		{"error field by message", logger.FilterField("error", "timeout"), 1},
+		{"level", logger.FilterLevel(log.LevelWarn), 1},
+		{"logger name", logger.Entries().FilterLoggerName("api"), 1},
+		{"chained", logger.FilterLevel(log.LevelInfo).FilterField("tenant", "globex"), 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.entries.Len() != test.expected {
+				t.Fatalf("expected %d entries, got %v", test.expected, test.entries)
+			}
+		})
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	logger := New()
+	warnLogger := logger.WithLevel(log.LevelWarn)
+	warnLogger.Info("skipped")
+	warnLogger.Infof("skipped %d", 1)
+	warnLogger.Warn("kept")
+
+	if entries := logger.Entries(); entries.Len() != 1 || entries[0].Message != "kept" {
+		t.Fatalf("unexpected entries %v", entries)
+	}
+	if warnLogger.Enabled(log.LevelInfo) || !warnLogger.Enabled(log.LevelError) {
+		t.Fatal("unexpected enabled levels")
+	}
+}
+
+func TestLoggerPanic(t *testing.T) {
+	logger := New()
+	defer func() {
+		if recovered := recover(); recovered != "boom" {
+			t.Fatalf("expected a panic, got %v", recovered)
+		}
+		logger.RequireLogged(t, log.LevelPanic, "boom")
+	}()
+	logger.Panic("boom")
+}
+
+func TestRequireLogged(t *testing.T) {
+	logger := New()
+	logger.Info("created", log.Int("order", 1))
+
+	tests := []struct {
+		name    string
+		level   log.Level
+		message string
+		fields  []log.Field
+		failed  bool
+	}{
+		{"match", log.LevelInfo, "created", []log.Field{log.Int("order", 1)}, false},
+		{"other level", log.LevelError, "created", nil, true},
+		{"other message", log.LevelInfo, "deleted", nil, true},
+		{"other field", log.LevelInfo, "created", []log.Field{log.Int("order", 2)}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := &fatalRecorder{TB: t}
+			logger.RequireLogged(recorder, test.level, test.message, test.fields...)
+			if failed := recorder.failure != ""; failed != test.failed {
+				t.Fatalf("expected failed to be %t, got %q", test.failed, recorder.failure)
+			}
+		})
+	}
+}
+
+func TestInstall(t *testing.T) {
+	logger := Install(t)
+
+	log.Global().Info("global")
+	log.ContextLogger(logger.Context(context.Background())).Info("context")
+
+	logger.RequireLogged(t, log.LevelInfo, "global")
+	logger.RequireLogged(t, log.LevelInfo, "context")
+}
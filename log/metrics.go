@@ -0,0 +1,116 @@
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// maxEntryCounters is the number of counters created before new logger names are counted as "other"
+const maxEntryCounters = 1000
+
+// entryKey identifies the counter of a level and logger name
+type entryKey struct {
+	level zapcore.Level
+	name  string
+}
+
+// entryCounters counts the entries logged by level and logger name
+var entryCounters sync.Map
+
+// entryCounterCount is the number of counters created
+var entryCounterCount atomic.Int64
+
+// EntryCount is the number of entries logged at a level by a logger.
+type EntryCount struct {
+	Level Level
+	Name  string
+	Count uint64
+}
+
+// countEntry is the zapcore hook counting the entries written by level and logger name.
+func countEntry(entry zapcore.Entry) error {
+	key := entryKey{entry.Level, entry.LoggerName}
+	counter, ok := entryCounters.Load(key)
+	if !ok {
+		if entryCounterCount.Add(1) > maxEntryCounters {
+			key.name = "other"
+		}
+		counter, _ = entryCounters.LoadOrStore(key, new(atomic.Uint64))
+	}
+	counter.(*atomic.Uint64).Add(1)
+	return nil
+}
+
+// EntryCounts returns the number of entries written since the process started, by level and
+// logger name. Entries dropped by sampling, rate limiting or overflow are counted by Dropped.
+func EntryCounts() []EntryCount {
+	var counts []EntryCount
+	entryCounters.Range(func(key, counter interface{}) bool {
+		entry := key.(entryKey)
+		counts = append(counts, EntryCount{
+			Level: levelFromZap(entry.level),
+			Name:  entry.name,
+			Count: counter.(*atomic.Uint64).Load(),
+		})
+		return true
+	})
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Name != counts[j].Name {
+			return counts[i].Name < counts[j].Name
+		}
+		return counts[i].Level < counts[j].Level
+	})
+	return counts
+}
+
+// WriteMetrics writes the entry counts and the dropped entries in the Prometheus text format.
+func WriteMetrics(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+
+	fmt.Fprintln(writer, "# HELP log_entries_total Number of log entries written by level and logger name.")
+	fmt.Fprintln(writer, "# TYPE log_entries_total counter")
+	for _, count := range EntryCounts() {
+		fmt.Fprintf(writer, "log_entries_total{level=%q,logger=%q} %d\n",
+			strings.ToLower(string(count.Level)), metricLabel(count.Name), count.Count)
+	}
+
+	stats := Dropped()
+	fmt.Fprintln(writer, "# HELP log_entries_dropped_total Number of log entries dropped by reason.")
+	fmt.Fprintln(writer, "# TYPE log_entries_dropped_total counter")
+	fmt.Fprintf(writer, "log_entries_dropped_total{reason=\"sampled\"} %d\n", stats.Sampled)
+	fmt.Fprintf(writer, "log_entries_dropped_total{reason=\"rate_limited\"} %d\n", stats.RateLimited)
+	fmt.Fprintf(writer, "log_entries_dropped_total{reason=\"duplicate\"} %d\n", stats.Duplicate)
+	fmt.Fprintf(writer, "log_entries_dropped_total{reason=\"overflow\"} %d\n", stats.Overflow)
+	fmt.Fprintf(writer, "log_entries_dropped_total{reason=\"http_sink\"} %d\n", stats.HTTPSink)
+	fmt.Fprintf(writer, "log_entries_dropped_total{reason=\"sentry\"} %d\n", stats.Sentry)
+
+	return writer.Flush()
+}
+
+// MetricsHandler returns an HTTP handler serving the metrics in the Prometheus text format.
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = WriteMetrics(w)
+	})
+}
+
+// metricLabel escapes the label value, which is then quoted with %q. Prometheus label values
+// escape backslashes, double quotes and line feeds only, so other characters are replaced.
+func metricLabel(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\n' || r > 0x7e {
+			return '_'
+		}
+		return r
+	}, value)
+}
@@ -0,0 +1,231 @@
+package log
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// namedLevels holds the levels configured for the named loggers derived from the global logger
+var namedLevels = newLevelRegistry()
+
+// Named returns the global logger with the supplied name.
+// Nested names are separated by dots, e.g. "payments.client".
+func Named(name string) Logger {
+	return Global().Named(name)
+}
+
+// SetNamedLevels replaces the levels of the named loggers derived from the global logger with the supplied spec.
+// The spec is a comma separated list of pattern=level pairs, e.g. "payments.*=debug,db=warn".
+func SetNamedLevels(spec string) error {
+	rules, err := parseNamedLevels(spec)
+	if err != nil {
+		return err
+	}
+	namedLevels.replace(rules)
+	return nil
+}
+
+// SetNamedLevel sets the level of the named loggers matching the pattern.
+func SetNamedLevel(pattern string, level Level) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid logger name pattern %q", pattern)
+	}
+	zapLevel, err := level.zapLevel()
+	if err != nil {
+		return err
+	}
+	namedLevels.set(pattern, zapLevel)
+	return nil
+}
+
+// RemoveNamedLevel removes the level of the named loggers matching the pattern,
+// so they inherit their level again.
+func RemoveNamedLevel(pattern string) {
+	namedLevels.remove(pattern)
+}
+
+// NamedLevels returns the levels configured for the named loggers by pattern.
+func NamedLevels() map[string]Level {
+	return namedLevels.snapshot()
+}
+
+// parseNamedLevels parses a comma separated list of pattern=level pairs.
+func parseNamedLevels(spec string) (map[string]zapcore.Level, error) {
+	rules := map[string]zapcore.Level{}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		pattern, level, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid named log level %q, expected pattern=level", pair)
+		}
+		pattern = strings.TrimSpace(pattern)
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return nil, fmt.Errorf("invalid logger name pattern %q", pattern)
+		}
+
+		zapLevel, err := Level(strings.TrimSpace(level)).zapLevel()
+		if err != nil {
+			return nil, err
+		}
+		rules[pattern] = zapLevel
+	}
+	return rules, nil
+}
+
+// namedLevel is the level resolved for a logger name.
+type namedLevel struct {
+	set   atomic.Bool
+	level atomic.Int32
+}
+
+// levelRegistry resolves the levels of named loggers from the configured patterns.
+type levelRegistry struct {
+	mu     sync.Mutex
+	rules  map[string]zapcore.Level
+	levels map[string]*namedLevel
+}
+
+// newLevelRegistry creates a new levelRegistry.
+func newLevelRegistry() *levelRegistry {
+	return &levelRegistry{
+		rules:  map[string]zapcore.Level{},
+		levels: map[string]*namedLevel{},
+	}
+}
+
+// enabler returns the level enabler of the name, falling back to the supplied
+// enabler when no pattern matches the name or any of its parents.
+func (r *levelRegistry) enabler(name string, fallback zapcore.LevelEnabler) zapcore.LevelEnabler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	level, ok := r.levels[name]
+	if !ok {
+		level = &namedLevel{}
+		r.resolve(name, level)
+		r.levels[name] = level
+	}
+
+	if fallback, ok := fallback.(*namedEnabler); ok {
+		return &namedEnabler{level, fallback.fallback}
+	}
+	return &namedEnabler{level, fallback}
+}
+
+// replace replaces all the rules.
+func (r *levelRegistry) replace(rules map[string]zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = rules
+	r.resolveAll()
+}
+
+// set sets the level of a pattern.
+func (r *levelRegistry) set(pattern string, level zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules[pattern] = level
+	r.resolveAll()
+}
+
+// remove removes the level of a pattern.
+func (r *levelRegistry) remove(pattern string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.rules, pattern)
+	r.resolveAll()
+}
+
+// snapshot returns a copy of the rules.
+func (r *levelRegistry) snapshot() map[string]Level {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	levels := make(map[string]Level, len(r.rules))
+	for pattern, level := range r.rules {
+		levels[pattern] = levelFromZap(level)
+	}
+	return levels
+}
+
+// resolveAll resolves the level of every known name. The caller must hold the lock.
+func (r *levelRegistry) resolveAll() {
+	for name, level := range r.levels {
+		r.resolve(name, level)
+	}
+}
+
+// resolve resolves the level of a name from the rules. The caller must hold the lock.
+// The name itself is matched first, then its parents from the nearest. For each name
+// an exact pattern takes precedence over globs, and longer globs over shorter ones.
+func (r *levelRegistry) resolve(name string, level *namedLevel) {
+	for current := name; current != ""; current = parentName(current) {
+		if zapLevel, ok := r.match(current); ok {
+			level.level.Store(int32(zapLevel))
+			level.set.Store(true)
+			return
+		}
+	}
+	level.set.Store(false)
+}
+
+// match returns the level of the most specific pattern matching the name. The caller must hold the lock.
+func (r *levelRegistry) match(name string) (zapcore.Level, bool) {
+	if level, ok := r.rules[name]; ok {
+		return level, true
+	}
+
+	var patterns []string
+	for pattern := range r.rules {
+		if matched, _ := path.Match(pattern, name); matched {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return 0, false
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	return r.rules[patterns[0]], true
+}
+
+// parentName returns the name of the parent logger, or an empty string for a root name.
+func parentName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// namedEnabler enables the levels configured for a name, or those of the fallback
+// when no level is configured.
+type namedEnabler struct {
+	level    *namedLevel
+	fallback zapcore.LevelEnabler
+}
+
+// Enabled returns true if the level is enabled.
+func (e *namedEnabler) Enabled(level zapcore.Level) bool {
+	if e.level.set.Load() {
+		return zapcore.Level(e.level.level.Load()).Enabled(level)
+	}
+	return e.fallback.Enabled(level)
+}
@@ -0,0 +1,94 @@
+package log
+
+import (
+	"fmt"
+	"os"
+)
+
+// NopLogger is a Logger discarding every entry.
+// Fatal still exits the process and Panic still panics.
+type NopLogger struct{}
+
+// NewNopLogger creates a new NopLogger.
+func NewNopLogger() Logger {
+	return NopLogger{}
+}
+
+// WithLevel returns the logger.
+func (l NopLogger) WithLevel(level Level) Logger {
+	return l
+}
+
+// WithField returns the logger.
+func (l NopLogger) WithField(key string, value interface{}) Logger {
+	return l
+}
+
+// WithFields returns the logger.
+func (l NopLogger) WithFields(fields Fields) Logger {
+	return l
+}
+
+// WithError returns the logger.
+func (l NopLogger) WithError(err error) Logger {
+	return l
+}
+
+// With returns the logger.
+func (l NopLogger) With(fields ...Field) Logger {
+	return l
+}
+
+// Named returns the logger.
+func (l NopLogger) Named(name string) Logger {
+	return l
+}
+
+// Enabled returns false as no level is enabled.
+func (l NopLogger) Enabled(level Level) bool {
+	return false
+}
+
+// Debug discards the message.
+func (l NopLogger) Debug(message string, fields ...Field) {}
+
+// Info discards the message.
+func (l NopLogger) Info(message string, fields ...Field) {}
+
+// Warn discards the message.
+func (l NopLogger) Warn(message string, fields ...Field) {}
+
+// Error discards the message.
+func (l NopLogger) Error(message string, fields ...Field) {}
+
+// Fatal exits the process.
+func (l NopLogger) Fatal(message string, fields ...Field) {
+	os.Exit(1)
+}
+
+// Panic panics with the message.
+func (l NopLogger) Panic(message string, fields ...Field) {
+	panic(message)
+}
+
+// Debugf discards the message.
+func (l NopLogger) Debugf(format string, args ...interface{}) {}
+
+// Infof discards the message.
+func (l NopLogger) Infof(format string, args ...interface{}) {}
+
+// Warnf discards the message.
+func (l NopLogger) Warnf(format string, args ...interface{}) {}
+
+// Errorf discards the message.
+func (l NopLogger) Errorf(format string, args ...interface{}) {}
+
+// Fatalf exits the process.
+func (l NopLogger) Fatalf(format string, args ...interface{}) {
+	os.Exit(1)
+}
+
+// Panicf panics with the formatted message.
+func (l NopLogger) Panicf(format string, args ...interface{}) {
+	panic(fmt.Sprintf(format, args...))
+}
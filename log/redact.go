@@ -0,0 +1,326 @@
+package log
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactStrategy is how redacted values are replaced.
+type RedactStrategy string
+
+const (
+	// RedactMask replaces redacted values with a placeholder.
+	RedactMask RedactStrategy = "mask"
+	// RedactHash replaces redacted values with a truncated SHA-256 hash, so equal values can still be correlated.
+	RedactHash RedactStrategy = "hash"
+)
+
+// redactedPlaceholder replaces masked values
+const redactedPlaceholder = "[REDACTED]"
+
+// defaultRedactKeys are the field names whose values are always redacted
+var defaultRedactKeys = []string{
+	"password", "passwd", "secret", "token", "authorization", "api_key", "apikey",
+	"cookie", "credential", "private_key",
+}
+
+// defaultRedactPatterns are the value patterns redacted from strings
+var defaultRedactPatterns = []RedactPattern{
+	{Name: "jwt", Pattern: regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+	{Name: "bearer", Pattern: regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/-]+=*`)},
+	{Name: "email", Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	{Name: "card", Pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), Validate: luhnValid},
+}
+
+// Redactable is implemented by types describing their own safe representation for logs.
+type Redactable interface {
+	Redact() interface{}
+}
+
+// RedactPattern is a pattern of values to redact from strings.
+type RedactPattern struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// Validate optionally confirms a match before it is redacted.
+	Validate func(match string) bool
+}
+
+// RedactionConfig is the configuration for redacting secrets and PII from log fields.
+type RedactionConfig struct {
+	// Enabled turns redaction on, it is off by default (LOG_REDACT).
+	Enabled bool
+	// Keys are field names redacted in addition to the defaults. A field is redacted
+	// when its lower cased name contains any of the keys.
+	Keys []string
+	// Strategy is how redacted values are replaced, mask by default.
+	Strategy RedactStrategy
+}
+
+// Redactor redacts secrets and PII from log fields.
+type Redactor struct {
+	keys     []string
+	patterns []RedactPattern
+	strategy RedactStrategy
+}
+
+// NewRedactor creates a new Redactor with the default keys and patterns.
+func NewRedactor(config RedactionConfig) (*Redactor, error) {
+	strategy := config.Strategy
+	if strategy == "" {
+		strategy = RedactMask
+	}
+	if strategy != RedactMask && strategy != RedactHash {
+		return nil, fmt.Errorf("invalid redact strategy %q", strategy)
+	}
+
+	keys := append([]string{}, defaultRedactKeys...)
+	for _, key := range config.Keys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	return &Redactor{
+		keys:     keys,
+		patterns: append([]RedactPattern{}, defaultRedactPatterns...),
+		strategy: strategy,
+	}, nil
+}
+
+// AddKey redacts the fields whose lower cased name contains the key.
+func (r *Redactor) AddKey(key string) *Redactor {
+	r.keys = append(r.keys, strings.ToLower(key))
+	return r
+}
+
+// AddPattern redacts the values matching the pattern from strings.
+func (r *Redactor) AddPattern(pattern RedactPattern) *Redactor {
+	r.patterns = append(r.patterns, pattern)
+	return r
+}
+
+// SensitiveKey returns true if the values of the field name are redacted.
+func (r *Redactor) SensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range r.keys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns the safe representation of the value of the named field.
+func (r *Redactor) Redact(key string, value interface{}) interface{} {
+	if redactable, ok := value.(Redactable); ok {
+		value = redactable.Redact()
+	}
+	if value == nil {
+		return nil
+	}
+	if r.SensitiveKey(key) {
+		return r.replace(fmt.Sprint(value), "")
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.RedactString(v)
+	case []byte:
+		return r.RedactString(string(v))
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case error:
+		return r.RedactString(v.Error())
+	case fmt.Stringer:
+		return r.RedactString(v.String())
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return r.RedactString(fmt.Sprint(value))
+	}
+	// numbers are decoded as json.Number, so large integers keep their precision
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return r.RedactString(string(data))
+	}
+	return r.redactGeneric(generic)
+}
+
+// RedactString redacts the values matching the patterns and the sensitive query parameters of URLs.
+func (r *Redactor) RedactString(value string) string {
+	value = r.redactURL(value)
+	for _, pattern := range r.patterns {
+		value = pattern.Pattern.ReplaceAllStringFunc(value, func(match string) string {
+			if pattern.Validate != nil && !pattern.Validate(match) {
+				return match
+			}
+			return r.replace(match, pattern.Name)
+		})
+	}
+	return value
+}
+
+// redactGeneric redacts a value decoded from JSON.
+func (r *Redactor) redactGeneric(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if r.SensitiveKey(key) && item != nil {
+				v[key] = r.replace(fmt.Sprint(item), "")
+			} else {
+				v[key] = r.redactGeneric(item)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactGeneric(item)
+		}
+		return v
+	case string:
+		return r.RedactString(v)
+	}
+	return value
+}
+
+// redactURL redacts the sensitive query parameters if the value is a URL.
+func (r *Redactor) redactURL(value string) string {
+	if !strings.Contains(value, "?") || !strings.Contains(value, "=") {
+		return value
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.RawQuery == "" {
+		return value
+	}
+
+	query := parsed.Query()
+	changed := false
+	for key, values := range query {
+		if !r.SensitiveKey(key) {
+			continue
+		}
+		for i := range values {
+			values[i] = r.replace(values[i], "")
+		}
+		changed = true
+	}
+	if !changed {
+		return value
+	}
+
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+// replace returns the replacement of a redacted value according to the strategy.
+func (r *Redactor) replace(value, kind string) string {
+	if r.strategy == RedactHash {
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(sum[:6])
+	}
+	if kind != "" {
+		return "[REDACTED:" + kind + "]"
+	}
+	return redactedPlaceholder
+}
+
+// field returns the redacted field, or the field itself if nothing is redacted.
+func (r *Redactor) field(field zapcore.Field) zapcore.Field {
+	switch field.Type {
+	case zapcore.StringType:
+		if r.SensitiveKey(field.Key) {
+			field.String = r.replace(field.String, "")
+		} else {
+			field.String = r.RedactString(field.String)
+		}
+		return field
+	case zapcore.SkipType, zapcore.NamespaceType, zapcore.BoolType:
+		return field
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok {
+			if message, _ := r.Redact(field.Key, err).(string); message != "" && message != err.Error() {
+				return zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: message}
+			}
+		}
+		return field
+	case zapcore.ReflectType, zapcore.StringerType:
+		if field.Interface != nil {
+			return zapcore.Field{Key: field.Key, Type: zapcore.ReflectType, Interface: r.Redact(field.Key, field.Interface)}
+		}
+	}
+
+	if redactable, ok := field.Interface.(Redactable); ok {
+		return zapcore.Field{Key: field.Key, Type: zapcore.ReflectType, Interface: r.Redact(field.Key, redactable)}
+	}
+	if r.SensitiveKey(field.Key) {
+		return zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: redactedPlaceholder}
+	}
+	return field
+}
+
+// fields returns the redacted fields.
+func (r *Redactor) fields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = r.field(field)
+	}
+	return redacted
+}
+
+// luhnValid returns true if the digits of the match pass the Luhn checksum.
+func luhnValid(match string) bool {
+	sum, double, digits := 0, false, 0
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+// redactCore redacts the fields and messages of the entries before they reach the underlying core.
+type redactCore struct {
+	zapcore.Core
+	redactor *Redactor
+}
+
+// With adds the redacted fields to the underlying core.
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactor.fields(fields)), redactor: c.redactor}
+}
+
+// Check adds the core to the checked entry if the level is enabled.
+func (c *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write writes the redacted entry to the underlying core.
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redactor.RedactString(entry.Message)
+	return writeThrough(c.Core, entry, c.redactor.fields(fields))
+}
@@ -0,0 +1,144 @@
+package log
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// testCredentials is a value describing its own safe representation.
+type testCredentials struct {
+	User     string
+	Password string
+}
+
+func (c testCredentials) Redact() interface{} {
+	return map[string]string{"user": c.User}
+}
+
+func TestRedactorRedact(t *testing.T) {
+	redactor, err := NewRedactor(RedactionConfig{Keys: []string{"ssn"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		key      string
+		value    interface{}
+		expected interface{}
+	}{
+		{"sensitive key", "password", "hunter2", redactedPlaceholder},
+		{"sensitive key case", "Authorization", "Basic abc", redactedPlaceholder},
+		{"sensitive key substring", "refresh_token", "abc", redactedPlaceholder},
+		{"configured key", "customer_ssn", "123-45-6789", redactedPlaceholder},
+		{"sensitive number", "secret", 42, redactedPlaceholder},
+		{"nil", "password", nil, nil},
+		{"plain", "order", "o-1", "o-1"},
+		{"number", "amount", 10, 10},
+		{"email", "note", "contact alice@example.com", "contact [REDACTED:email]"},
+		{"jwt", "note", "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.abc", "[REDACTED:jwt]"},
+		{"bearer", "header", "Bearer abc.def", "[REDACTED:bearer]"},
+		{"card", "note", "card 4111 1111 1111 1111", "card [REDACTED:card]"},
+		{"not a card", "note", "order 1234 5678 9012 3456", "order 1234 5678 9012 3456"},
+		{"url", "url", "https://api.example.com/orders?token=abc&page=2", "https://api.example.com/orders?page=2&token=%5BREDACTED%5D"},
+		{"bytes", "body", []byte("alice@example.com"), "[REDACTED:email]"},
+		{"error", "error", errors.New("failed for alice@example.com"), "failed for [REDACTED:email]"},
+		{
+			"nested",
+			"request",
+			map[string]interface{}{"user": "alice", "auth": map[string]interface{}{"password": "hunter2"}, "ids": []interface{}{"alice@example.com"}},
+			map[string]interface{}{"user": "alice", "auth": map[string]interface{}{"password": redactedPlaceholder}, "ids": []interface{}{"[REDACTED:email]"}},
+		},
+		{"redactable", "login", testCredentials{"alice", "hunter2"}, map[string]interface{}{"user": "alice"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if redacted := redactor.Redact(test.key, test.value); !reflect.DeepEqual(redacted, test.expected) {
+				t.Fatalf("expected %#v, got %#v", test.expected, redacted)
+			}
+		})
+	}
+}
+
+func TestRedactorHash(t *testing.T) {
+	redactor, err := NewRedactor(RedactionConfig{Strategy: RedactHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := redactor.Redact("password", "hunter2").(string)
+	second, _ := redactor.Redact("password", "hunter2").(string)
+	other, _ := redactor.Redact("password", "hunter3").(string)
+	if !strings.HasPrefix(first, "sha256:") || strings.Contains(first, "hunter2") {
+		t.Fatalf("unexpected hash %q", first)
+	}
+	if first != second || first == other {
+		t.Fatalf("expected equal values to share a hash, got %q %q %q", first, second, other)
+	}
+
+	if _, err := NewRedactor(RedactionConfig{Strategy: "drop"}); err == nil {
+		t.Fatal("expected an invalid strategy to be rejected")
+	}
+}
+
+func TestRedactorAddPattern(t *testing.T) {
+	redactor, err := NewRedactor(RedactionConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	redactor.AddKey("Pin").AddPattern(RedactPattern{Name: "iban", Pattern: regexp.MustCompile(`\bGB\d{2}[A-Z]{4}\d{14}\b`)})
+
+	if redacted := redactor.RedactString("pay GB29NWBK60161331926819"); redacted != "pay [REDACTED:iban]" {
+		t.Fatalf("unexpected redacted string %q", redacted)
+	}
+	if !redactor.SensitiveKey("card_pin") {
+		t.Fatal("expected the added key to be sensitive")
+	}
+}
+
+func TestRedactCore(t *testing.T) {
+	redactor, err := NewRedactor(RedactionConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	observed, logs := observer.New(zapcore.DebugLevel)
+	core := (&redactCore{Core: observed, redactor: redactor}).With([]zapcore.Field{
+		{Key: "token", Type: zapcore.StringType, String: "abc"},
+	})
+
+	fields := []zapcore.Field{
+		{Key: "password", Type: zapcore.StringType, String: "hunter2"},
+		{Key: "secret", Type: zapcore.Int64Type, Integer: 42},
+		{Key: "user", Type: zapcore.ReflectType, Interface: map[string]string{"email": "alice@example.com"}},
+		{Key: "error", Type: zapcore.ErrorType, Interface: errors.New("no account for alice@example.com")},
+	}
+	entry := zapcore.Entry{Level: zapcore.InfoLevel, Message: "signed in alice@example.com"}
+	if err := writeThrough(core, entry, fields); err != nil {
+		t.Fatal(err)
+	}
+
+	logged := logs.All()
+	if len(logged) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(logged))
+	}
+	if logged[0].Message != "signed in [REDACTED:email]" {
+		t.Fatalf("unexpected message %q", logged[0].Message)
+	}
+	expected := map[string]interface{}{
+		"token":    redactedPlaceholder,
+		"password": redactedPlaceholder,
+		"secret":   redactedPlaceholder,
+		"user":     map[string]interface{}{"email": "[REDACTED:email]"},
+		"error":    "no account for [REDACTED:email]",
+	}
+	if context := logged[0].ContextMap(); !reflect.DeepEqual(context, expected) {
+		t.Fatalf("expected %v, got %v", expected, context)
+	}
+}
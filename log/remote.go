@@ -0,0 +1,123 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// JournaldConfig is the configuration for the journald sink.
+type JournaldConfig struct {
+	// Enabled turns the journald sink on.
+	Enabled bool
+	// Socket is the journald native protocol socket, /run/systemd/journal/socket by default.
+	Socket string
+	// Identifier is the SYSLOG_IDENTIFIER of the entries, the program name by default.
+	Identifier string
+}
+
+// sinkEntry is an encoded entry decoded by a remote sink.
+type sinkEntry struct {
+	line    []byte
+	level   zapcore.Level
+	message string
+	time    time.Time
+	fields  map[string]interface{}
+}
+
+// parseSinkEntries splits the encoded entries written to a sink into lines and decodes them.
+// Lines that are not JSON objects, as written by the console encoder, are info entries with
+// the line as their message.
+func parseSinkEntries(p []byte) []sinkEntry {
+	var entries []sinkEntry
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		entry := sinkEntry{line: append([]byte{}, line...), level: zapcore.InfoLevel, message: string(line), time: time.Now()}
+		if err := json.Unmarshal(line, &entry.fields); err == nil {
+			if level, ok := entry.fields["level"].(string); ok {
+				_ = entry.level.UnmarshalText([]byte(level))
+			}
+			if message, ok := entry.fields["message"].(string); ok {
+				entry.message = message
+			}
+			if value, ok := entry.fields["time"].(string); ok {
+				if parsed, err := time.Parse("2006-01-02T15:04:05.000Z0700", value); err == nil {
+					entry.time = parsed
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// syslogSeverity returns the syslog severity of the level, which is also the journald priority.
+func syslogSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	case zapcore.DPanicLevel:
+		return 2
+	case zapcore.PanicLevel:
+		return 1
+	case zapcore.FatalLevel:
+		return 0
+	}
+	return 5
+}
+
+// sinkFieldString returns the value of a decoded field as a string, JSON encoding non-strings.
+func sinkFieldString(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// newRemoteSinks creates the configured remote sinks.
+func newRemoteSinks(config *Config) ([]zapcore.WriteSyncer, error) {
+	var sinks []zapcore.WriteSyncer
+
+	if config.SyslogSink().Enabled() {
+		sink, err := NewSyslogSink(config.SyslogSink())
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create the syslog sink. %s", err)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if config.JournaldSink().Enabled {
+		sink, err := NewJournaldSink(config.JournaldSink())
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create the journald sink. %s", err)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if config.HTTPSink().Enabled() {
+		sink, err := NewHTTPSink(config.HTTPSink())
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create the HTTP log sink. %s", err)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	return sinks, nil
+}
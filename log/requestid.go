@@ -0,0 +1,87 @@
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+)
+
+const (
+	// RequestIDKey is the key of the request id in key-value stores such as gin.Context,
+	// and the log field holding the request id.
+	RequestIDKey = "request_id"
+	// maxRequestIDLength is the maximum length of a request id accepted from a client
+	maxRequestIDLength = 128
+	// crockfordAlphabet is the Crockford base32 alphabet of ULIDs
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// ContextWithRequestID returns a new context with the request id.
+// The context supplied is never modified.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// RequestIDFromContext returns the request id of the context, or an empty string.
+// The request_id baggage field is never used, the baggage being set by the clients.
+func RequestIDFromContext(ctx context.Context) string {
+	if requestID, ok := ctx.Value(requestIDContextKey).(string); ok {
+		return requestID
+	}
+	requestID, _ := ctx.Value(RequestIDKey).(string)
+	return requestID
+}
+
+// ValidRequestID returns true if the request id received from a client can be trusted:
+// not empty, at most 128 characters and printable ASCII without spaces.
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// NewRequestID returns a new UUIDv7 request id, ordered by creation time.
+func NewRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id[6:])
+	putMillis(id, time.Now())
+	id[6] = id[6]&0x0f | 0x70
+	id[8] = id[8]&0x3f | 0x80
+
+	encoded := hex.EncodeToString(id)
+	return encoded[:8] + "-" + encoded[8:12] + "-" + encoded[12:16] + "-" + encoded[16:20] + "-" + encoded[20:]
+}
+
+// NewULID returns a new ULID, an alternative request id ordered by creation time.
+func NewULID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id[6:])
+	putMillis(id, time.Now())
+
+	// 128 bits are encoded as 26 base32 characters, the first one holding 3 bits
+	encoded := make([]byte, 26)
+	high, low := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+	for i := 25; i >= 0; i-- {
+		encoded[i] = crockfordAlphabet[low&0x1f]
+		low = low>>5 | high<<59
+		high >>= 5
+	}
+	return string(encoded)
+}
+
+// putMillis writes the Unix time in milliseconds to the first 48 bits of the id.
+func putMillis(id []byte, now time.Time) {
+	millis := uint64(now.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		id[i] = byte(millis)
+		millis >>= 8
+	}
+}
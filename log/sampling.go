@@ -0,0 +1,403 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// maxRateLimitKeys is the number of rate limit keys tracked before the limiter is reset
+const maxRateLimitKeys = 10000
+
+// dropped counts the entries dropped by sampling, rate limiting, duplicate suppression,
+// asynchronous buffer overflows, the HTTP sink and the Sentry hook
+var dropped struct {
+	sampled     atomic.Uint64
+	rateLimited atomic.Uint64
+	duplicate   atomic.Uint64
+	overflow    atomic.Uint64
+	httpSink    atomic.Uint64
+	sentry      atomic.Uint64
+}
+
+// DropStats is the number of log entries dropped by reason.
+type DropStats struct {
+	Sampled     uint64 `json:"sampled"`
+	RateLimited uint64 `json:"rate_limited"`
+	Duplicate   uint64 `json:"duplicate"`
+	Overflow    uint64 `json:"overflow"`
+	HTTPSink    uint64 `json:"http_sink"`
+	Sentry      uint64 `json:"sentry"`
+}
+
+// Dropped returns the number of log entries dropped since the process started.
+func Dropped() DropStats {
+	return DropStats{
+		Sampled:     dropped.sampled.Load(),
+		RateLimited: dropped.rateLimited.Load(),
+		Duplicate:   dropped.duplicate.Load(),
+		Overflow:    dropped.overflow.Load(),
+		HTTPSink:    dropped.httpSink.Load(),
+		Sentry:      dropped.sentry.Load(),
+	}
+}
+
+// SamplingConfig is the configuration for sampling, rate limiting and duplicate suppression.
+type SamplingConfig struct {
+	// Initial is the number of entries with the same level and message logged per Interval
+	// before sampling starts. Sampling is disabled when zero.
+	Initial int
+	// Thereafter logs every Thereafter-th entry once Initial entries are logged in the Interval.
+	Thereafter int
+	// Interval is the sampling interval.
+	Interval time.Duration
+	// RateLimit is the number of entries per second allowed for each key. Rate limiting is disabled when zero.
+	RateLimit float64
+	// RateBurst is the number of entries allowed to exceed the rate limit in a burst.
+	RateBurst int
+	// RateLimitKey is the field whose value is the rate limit key. The message is the key when empty
+	// or when the entry has no such field.
+	RateLimitKey string
+	// DedupWindow suppresses consecutive identical entries within the window and logs a
+	// "repeated N times" summary instead. Duplicate suppression is disabled when zero.
+	DedupWindow time.Duration
+}
+
+// newSamplingCore wraps the core with the sampling, rate limiting and duplicate suppression
+// configured. Entries at DPanic level and above are never dropped.
+func newSamplingCore(core zapcore.Core, config SamplingConfig) zapcore.Core {
+	if config.Initial > 0 {
+		interval := config.Interval
+		if interval <= 0 {
+			interval = time.Second
+		}
+		core = zapcore.NewSamplerWithOptions(core, interval, config.Initial, config.Thereafter,
+			zapcore.SamplerHook(func(entry zapcore.Entry, decision zapcore.SamplingDecision) {
+				if decision&zapcore.LogDropped != 0 {
+					dropped.sampled.Add(1)
+				}
+			}),
+		)
+	}
+
+	if config.RateLimit > 0 {
+		core = &rateLimitCore{
+			Core:    core,
+			limiter: newRateLimiter(config.RateLimit, config.RateBurst),
+			field:   config.RateLimitKey,
+		}
+	}
+
+	if config.DedupWindow > 0 {
+		core = &dedupCore{
+			Core:  core,
+			state: &dedupState{window: config.DedupWindow},
+		}
+	}
+
+	return core
+}
+
+// writeThrough writes the entry to the core if the core accepts it, returning the write errors
+// of the cores so the logger reports them.
+func writeThrough(core zapcore.Core, entry zapcore.Entry, fields []zapcore.Field) error {
+	checked := core.Check(entry, nil)
+	if checked == nil {
+		return nil
+	}
+	output := &writeErrorOutput{}
+	checked.ErrorOutput = output
+	checked.Write(fields...)
+	return output.err
+}
+
+// writeErrorOutput is the error output of a checked entry, keeping the write errors it reports.
+type writeErrorOutput struct {
+	err error
+}
+
+// Write keeps the write error reported by the checked entry.
+func (o *writeErrorOutput) Write(message []byte) (int, error) {
+	// the message is formatted as "<time> write error: <error>"
+	_, reported, _ := strings.Cut(strings.TrimSpace(string(message)), " write error: ")
+	o.err = errors.Join(o.err, errors.New(reported))
+	return len(message), nil
+}
+
+// Sync does nothing.
+func (o *writeErrorOutput) Sync() error {
+	return nil
+}
+
+// rateLimiter is a token bucket limiter per key.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// tokenBucket is the state of a single key of the rateLimiter.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a new rateLimiter.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: map[string]*tokenBucket{},
+	}
+}
+
+// allow returns true if an entry with the key is allowed at the supplied time.
+func (l *rateLimiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxRateLimitKeys {
+			l.buckets = map[string]*tokenBucket{}
+		}
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.last).Seconds() * l.rate
+	if bucket.tokens > l.burst {
+		bucket.tokens = l.burst
+	}
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// rateLimitCore drops the entries exceeding the rate limit of their key.
+type rateLimitCore struct {
+	zapcore.Core
+	limiter *rateLimiter
+	field   string
+	value   string
+}
+
+// With adds fields to the underlying core, remembering the rate limit key if present.
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(fields)
+	if value, ok := fieldValue(fields, c.field); ok {
+		clone.value = value
+	}
+	return &clone
+}
+
+// Check adds the core to the checked entry if the level is enabled.
+func (c *rateLimitCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write writes the entry to the underlying core unless its key exceeds the rate limit.
+func (c *rateLimitCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if entry.Level < zapcore.DPanicLevel {
+		key := c.value
+		if value, ok := fieldValue(fields, c.field); ok {
+			key = value
+		}
+		if key == "" {
+			key = entry.Message
+		}
+
+		if !c.limiter.allow(key, entry.Time) {
+			dropped.rateLimited.Add(1)
+			return nil
+		}
+	}
+
+	return writeThrough(c.Core, entry, fields)
+}
+
+// fieldValue returns the value of the field with the key as a string.
+func fieldValue(fields []zapcore.Field, key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	for _, field := range fields {
+		if field.Key == key {
+			return fieldString(field), true
+		}
+	}
+	return "", false
+}
+
+// fieldString returns a string representation of the field value.
+func fieldString(field zapcore.Field) string {
+	if field.Type == zapcore.StringType {
+		return field.String
+	}
+	if field.Interface != nil {
+		return fmt.Sprint(field.Interface)
+	}
+	if field.String != "" {
+		return field.String
+	}
+	return fmt.Sprint(field.Integer)
+}
+
+// dedupState is the state of duplicate suppression shared by a core and its clones.
+type dedupState struct {
+	window time.Duration
+
+	mu    sync.Mutex
+	key   string
+	first time.Time
+	entry zapcore.Entry
+	core  zapcore.Core
+	count int
+	timer *time.Timer
+}
+
+// take returns the summary of the suppressed entries and resets the count. The caller must hold the lock.
+func (s *dedupState) take() (zapcore.Core, zapcore.Entry, []zapcore.Field, bool) {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.count == 0 {
+		return nil, zapcore.Entry{}, nil, false
+	}
+
+	entry := s.entry
+	entry.Time = time.Now()
+	entry.Message = fmt.Sprintf("last message repeated %d times", s.count)
+	fields := []zapcore.Field{
+		{Key: "repeated_message", Type: zapcore.StringType, String: s.entry.Message},
+		{Key: "repeated", Type: zapcore.Int64Type, Integer: int64(s.count)},
+	}
+	s.count = 0
+	return s.core, entry, fields, true
+}
+
+// flush writes the summary of the suppressed entries, if any.
+func (s *dedupState) flush() {
+	s.mu.Lock()
+	core, entry, fields, ok := s.take()
+	s.mu.Unlock()
+
+	if ok {
+		// the summary may be written by a timer, with no caller to return the error to
+		if err := writeThrough(core, entry, fields); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write the log summary, %s\n", err)
+		}
+	}
+}
+
+// dedupCore suppresses consecutive identical entries.
+type dedupCore struct {
+	zapcore.Core
+	state *dedupState
+	// context is the key of the fields added with With, so entries of loggers with different
+	// fields, e.g. of different requests, are not duplicates
+	context string
+}
+
+// With adds fields to the underlying core.
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	var context strings.Builder
+	context.WriteString(c.context)
+	writeFieldsKey(&context, fields)
+	return &dedupCore{Core: c.Core.With(fields), state: c.state, context: context.String()}
+}
+
+// Check adds the core to the checked entry if the level is enabled.
+func (c *dedupCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write writes the entry unless it duplicates the previous entry within the window.
+func (c *dedupCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if entry.Level >= zapcore.DPanicLevel {
+		c.state.flush()
+		return writeThrough(c.Core, entry, fields)
+	}
+
+	key := dedupKey(c.context, entry, fields)
+	state := c.state
+
+	state.mu.Lock()
+	if key == state.key && entry.Time.Sub(state.first) < state.window {
+		state.count++
+		if state.timer == nil {
+			state.timer = time.AfterFunc(state.window-entry.Time.Sub(state.first), state.flush)
+		}
+		state.mu.Unlock()
+		dropped.duplicate.Add(1)
+		return nil
+	}
+
+	summaryCore, summary, summaryFields, ok := state.take()
+	state.key = key
+	state.first = entry.Time
+	state.entry = entry
+	state.core = c.Core
+	state.mu.Unlock()
+
+	var err error
+	if ok {
+		err = writeThrough(summaryCore, summary, summaryFields)
+	}
+	return errors.Join(err, writeThrough(c.Core, entry, fields))
+}
+
+// Sync writes the pending summary and syncs the underlying core.
+func (c *dedupCore) Sync() error {
+	c.state.flush()
+	return c.Core.Sync()
+}
+
+// dedupKey returns the key identifying duplicate entries of the loggers with the context.
+func dedupKey(context string, entry zapcore.Entry, fields []zapcore.Field) string {
+	var key strings.Builder
+	key.WriteString(entry.Level.String())
+	key.WriteByte('|')
+	key.WriteString(entry.LoggerName)
+	key.WriteByte('|')
+	key.WriteString(entry.Message)
+	key.WriteString(context)
+	writeFieldsKey(&key, fields)
+	return key.String()
+}
+
+// writeFieldsKey writes the keys and values of the fields to the dedup key.
+func writeFieldsKey(key *strings.Builder, fields []zapcore.Field) {
+	for _, field := range fields {
+		if field.Type == zapcore.SkipType {
+			continue
+		}
+		key.WriteByte('|')
+		key.WriteString(field.Key)
+		key.WriteByte('=')
+		key.WriteString(fieldString(field))
+	}
+}
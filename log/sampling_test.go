@@ -0,0 +1,134 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// testLogEntry is an entry written by the sampling tests.
+type testLogEntry struct {
+	message string
+	key     string
+}
+
+// failingSyncer is a write syncer failing every write.
+type failingSyncer struct{}
+
+func (failingSyncer) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func (failingSyncer) Sync() error {
+	return nil
+}
+
+func TestSamplingCore(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		config   SamplingConfig
+		entries  []testLogEntry
+		expected []string
+		dropped  func(before, after DropStats) bool
+	}{
+		{
+			name:     "disabled",
+			entries:  []testLogEntry{{message: "a"}, {message: "a"}, {message: "a"}},
+			expected: []string{"a", "a", "a"},
+		},
+		{
+			name:     "sampling",
+			config:   SamplingConfig{Initial: 1, Thereafter: 2, Interval: time.Minute},
+			entries:  []testLogEntry{{message: "a"}, {message: "a"}, {message: "a"}, {message: "b"}},
+			expected: []string{"a", "a", "b"},
+			dropped:  func(before, after DropStats) bool { return after.Sampled-before.Sampled == 1 },
+		},
+		{
+			name:     "rate limit by message",
+			config:   SamplingConfig{RateLimit: 0.001, RateBurst: 2},
+			entries:  []testLogEntry{{message: "a"}, {message: "a"}, {message: "a"}, {message: "b"}},
+			expected: []string{"a", "a", "b"},
+			dropped:  func(before, after DropStats) bool { return after.RateLimited-before.RateLimited == 1 },
+		},
+		{
+			name:     "rate limit by key",
+			config:   SamplingConfig{RateLimit: 0.001, RateBurst: 1, RateLimitKey: "tenant"},
+			entries:  []testLogEntry{{"a", "acme"}, {"b", "acme"}, {"a", "globex"}},
+			expected: []string{"a", "a"},
+		},
+		{
+			name:     "dedup",
+			config:   SamplingConfig{DedupWindow: time.Minute},
+			entries:  []testLogEntry{{message: "a"}, {message: "a"}, {message: "a"}, {message: "b"}},
+			expected: []string{"a", "last message repeated 2 times", "b"},
+			dropped:  func(before, after DropStats) bool { return after.Duplicate-before.Duplicate == 2 },
+		},
+		{
+			name:     "dedup with different fields",
+			config:   SamplingConfig{DedupWindow: time.Minute},
+			entries:  []testLogEntry{{"a", "acme"}, {"a", "globex"}},
+			expected: []string{"a", "a"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			observed, logs := observer.New(zapcore.DebugLevel)
+			core := newSamplingCore(observed, test.config)
+
+			before := Dropped()
+			for _, entry := range test.entries {
+				var fields []zapcore.Field
+				if entry.key != "" {
+					fields = append(fields, zapcore.Field{Key: "tenant", Type: zapcore.StringType, String: entry.key})
+				}
+				if err := writeThrough(core, zapcore.Entry{Level: zapcore.InfoLevel, Time: now, Message: entry.message}, fields); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := core.Sync(); err != nil {
+				t.Fatal(err)
+			}
+
+			var messages []string
+			for _, logged := range logs.All() {
+				messages = append(messages, logged.Message)
+			}
+			if strings.Join(messages, ",") != strings.Join(test.expected, ",") {
+				t.Fatalf("expected %v, got %v", test.expected, messages)
+			}
+			if test.dropped != nil && !test.dropped(before, Dropped()) {
+				t.Fatalf("unexpected drop counts %+v then %+v", before, Dropped())
+			}
+		})
+	}
+}
+
+func TestSamplingCoreKeepsPanics(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	core := newSamplingCore(observed, SamplingConfig{RateLimit: 0.001, RateBurst: 1, DedupWindow: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		if err := writeThrough(core, zapcore.Entry{Level: zapcore.DPanicLevel, Time: time.Now(), Message: "a"}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if logs.Len() != 3 {
+		t.Fatalf("expected 3 entries, got %d", logs.Len())
+	}
+}
+
+func TestWriteThroughReturnsWriteErrors(t *testing.T) {
+	encoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "message"})
+	core := newSamplingCore(zapcore.NewCore(encoder, failingSyncer{}, zapcore.DebugLevel), SamplingConfig{RateLimit: 100, DedupWindow: time.Minute})
+
+	err := writeThrough(core, zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "a"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+}
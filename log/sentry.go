@@ -0,0 +1,378 @@
+package log
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sentryTagKeys are the fields sent as Sentry tags, the other fields are sent as extra data
+var sentryTagKeys = []string{RequestIDKey, TraceIDKey, SpanIDKey, "source_program", "tenant_id", "user_id"}
+
+// SentryConfig is the configuration for the Sentry hook.
+type SentryConfig struct {
+	// DSN is the Sentry DSN, https://<key>@<host>/<project>.
+	DSN string
+	// Environment is the environment of the events.
+	Environment string
+	// Release is the release of the events.
+	Release string
+	// ServerName is the server name of the events, the host name by default.
+	ServerName string
+	// Levels are the levels forwarded, error, fatal and panic by default.
+	Levels []Level
+	// QueueSize is the number of events queued before new events are dropped.
+	QueueSize int
+	// Timeout is the timeout of each request.
+	Timeout time.Duration
+	// DedupWindow suppresses the events with the fingerprint of an event sent within the window.
+	DedupWindow time.Duration
+	// FlushTimeout is how long Sync waits for the queued events to be sent, 2s by default.
+	FlushTimeout time.Duration
+	// Client is the HTTP client sending the events, a client with the timeout by default.
+	Client *http.Client
+}
+
+// SentryHook is a Hook forwarding entries to Sentry, or a Sentry compatible error tracker, from
+// a background goroutine. The events carry the stack trace, the request context and a fingerprint
+// made of the logger name, the error type and the message, or of the "fingerprint" field.
+type SentryHook struct {
+	config     SentryConfig
+	client     *http.Client
+	endpoint   string
+	auth       string
+	serverName string
+
+	queue   chan sentryItem
+	wg      sync.WaitGroup
+	queueMu sync.RWMutex
+	closed  bool
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// sentryItem is a queued event, or a flush marker closed once the events queued before it are sent
+type sentryItem struct {
+	event   map[string]interface{}
+	flushed chan struct{}
+}
+
+// NewSentryHook creates a new SentryHook and starts its background goroutine.
+func NewSentryHook(config SentryConfig) (*SentryHook, error) {
+	dsn, err := url.Parse(config.DSN)
+	if err != nil || dsn.User == nil || dsn.Host == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN %q", config.DSN)
+	}
+	path := strings.TrimSuffix(dsn.Path, "/")
+	project := path[strings.LastIndex(path, "/")+1:]
+	if project == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN %q, the project is missing", config.DSN)
+	}
+
+	if len(config.Levels) == 0 {
+		config.Levels = []Level{LevelError, LevelFatal, LevelPanic}
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 100
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.FlushTimeout <= 0 {
+		config.FlushTimeout = 2 * time.Second
+	}
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: config.Timeout}
+	}
+	serverName := config.ServerName
+	if serverName == "" {
+		serverName, _ = os.Hostname()
+	}
+
+	hook := &SentryHook{
+		config:     config,
+		client:     client,
+		endpoint:   fmt.Sprintf("%s://%s%s/api/%s/store/", dsn.Scheme, dsn.Host, strings.TrimSuffix(path, "/"+project), project),
+		auth:       fmt.Sprintf("Sentry sentry_version=7, sentry_client=sen-go/1.0, sentry_key=%s", dsn.User.Username()),
+		serverName: serverName,
+		queue:      make(chan sentryItem, config.QueueSize),
+		seen:       map[string]time.Time{},
+	}
+	hook.wg.Add(1)
+	go hook.run()
+	return hook, nil
+}
+
+// Levels returns the levels forwarded.
+func (h *SentryHook) Levels() []Level {
+	return h.config.Levels
+}
+
+// Fire queues the entry as a Sentry event, dropping it if the queue is full, the hook is closed
+// or the fingerprint was sent within the dedup window.
+func (h *SentryHook) Fire(entry HookEntry) error {
+	fingerprint := sentryFingerprint(entry)
+	if h.duplicate(strings.Join(fingerprint, "|"), entry.Time) {
+		return nil
+	}
+
+	h.queueMu.RLock()
+	defer h.queueMu.RUnlock()
+
+	if h.closed {
+		dropped.sentry.Add(1)
+		return nil
+	}
+	select {
+	case h.queue <- sentryItem{event: h.event(entry, fingerprint)}:
+		return nil
+	default:
+		dropped.sentry.Add(1)
+		return errors.New("the Sentry queue is full")
+	}
+}
+
+// Sync waits until the events queued before the call are sent, or the flush timeout elapses.
+// It is called before the process exits on fatal and panic entries.
+func (h *SentryHook) Sync() error {
+	timer := time.NewTimer(h.config.FlushTimeout)
+	defer timer.Stop()
+
+	flushed := make(chan struct{})
+	h.queueMu.RLock()
+	if h.closed {
+		h.queueMu.RUnlock()
+		return nil
+	}
+	select {
+	case h.queue <- sentryItem{flushed: flushed}:
+		h.queueMu.RUnlock()
+	case <-timer.C:
+		h.queueMu.RUnlock()
+		return errors.New("timed out flushing the Sentry queue")
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-timer.C:
+		return errors.New("timed out flushing the Sentry queue")
+	}
+}
+
+// Close sends the queued events and stops the background goroutine. The entries fired
+// after Close are dropped.
+func (h *SentryHook) Close() error {
+	h.queueMu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.queue)
+	}
+	h.queueMu.Unlock()
+
+	h.wg.Wait()
+	return nil
+}
+
+// run sends the queued events.
+func (h *SentryHook) run() {
+	defer h.wg.Done()
+
+	for item := range h.queue {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		if err := h.send(item.event); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to send the Sentry event, %s\n", err)
+		}
+	}
+}
+
+// send posts the event to the store endpoint.
+func (h *SentryHook) send(event map[string]interface{}) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, h.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Sentry-Auth", h.auth)
+
+	response, err := h.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return nil
+}
+
+// duplicate returns true if the fingerprint was sent within the dedup window.
+func (h *SentryHook) duplicate(fingerprint string, now time.Time) bool {
+	if h.config.DedupWindow <= 0 {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if sent, ok := h.seen[fingerprint]; ok && now.Sub(sent) < h.config.DedupWindow {
+		return true
+	}
+	if len(h.seen) >= maxRateLimitKeys {
+		h.seen = map[string]time.Time{}
+	}
+	h.seen[fingerprint] = now
+	return false
+}
+
+// event returns the Sentry event of the entry.
+func (h *SentryHook) event(entry HookEntry, fingerprint []string) map[string]interface{} {
+	tags := map[string]string{}
+	extra := map[string]interface{}{}
+	for key, value := range entry.Fields {
+		extra[key] = value
+	}
+	for _, key := range sentryTagKeys {
+		if value, ok := extra[key]; ok && value != nil {
+			tags[key] = fmt.Sprint(value)
+			delete(extra, key)
+		}
+	}
+	if entry.Caller != "" {
+		extra["caller"] = entry.Caller
+	}
+
+	event := map[string]interface{}{
+		"event_id":    newEventID(),
+		"timestamp":   entry.Time.UTC().Format(time.RFC3339Nano),
+		"level":       sentryLevel(entry.Level),
+		"logger":      entry.LoggerName,
+		"platform":    "go",
+		"message":     entry.Message,
+		"server_name": h.serverName,
+		"tags":        tags,
+		"extra":       extra,
+		"fingerprint": fingerprint,
+	}
+	if h.config.Environment != "" {
+		event["environment"] = h.config.Environment
+	}
+	if h.config.Release != "" {
+		event["release"] = h.config.Release
+	}
+	if traceID, ok := tags[TraceIDKey]; ok {
+		event["contexts"] = map[string]interface{}{
+			"trace": map[string]string{"trace_id": traceID, "span_id": tags[SpanIDKey]},
+		}
+	}
+
+	// the stack trace of the error, where it was wrapped, takes precedence over the log call
+	stack, _ := extra["error_stack"].(string)
+	if stack != "" {
+		delete(extra, "error_stack")
+	} else {
+		stack = entry.Stack
+	}
+	stacktrace := map[string]interface{}{"frames": sentryFrames(stack)}
+
+	if message, ok := extra["error"].(string); ok {
+		errorType, _ := extra["error_type"].(string)
+		delete(extra, "error")
+		delete(extra, "error_type")
+		event["exception"] = map[string]interface{}{
+			"values": []map[string]interface{}{{"type": errorType, "value": message, "stacktrace": stacktrace}},
+		}
+	} else {
+		event["threads"] = map[string]interface{}{
+			"values": []map[string]interface{}{{"current": true, "crashed": false, "stacktrace": stacktrace}},
+		}
+	}
+
+	return event
+}
+
+// sentryFingerprint returns the fingerprint of the entry, its "fingerprint" field if any.
+func sentryFingerprint(entry HookEntry) []string {
+	switch fingerprint := entry.Fields["fingerprint"].(type) {
+	case string:
+		return []string{fingerprint}
+	case []string:
+		return fingerprint
+	}
+
+	errorType, _ := entry.Fields["error_type"].(string)
+	return []string{entry.LoggerName, errorType, entry.Message}
+}
+
+// sentryFrames parses a stack trace of functions each followed by a tab indented file:line,
+// as captured by zap and WithStack, into Sentry frames ordered from the oldest call.
+func sentryFrames(stack string) []map[string]interface{} {
+	lines := strings.Split(strings.TrimSpace(stack), "\n")
+	var frames []map[string]interface{}
+	for i := 0; i+1 < len(lines); i += 2 {
+		location := strings.TrimSpace(lines[i+1])
+		file, line := location, 0
+		if index := strings.LastIndex(location, ":"); index > 0 {
+			file = location[:index]
+			line, _ = strconv.Atoi(location[index+1:])
+		}
+		function := strings.TrimSpace(lines[i])
+		frames = append(frames, map[string]interface{}{
+			"function": function,
+			"abs_path": file,
+			"filename": file,
+			"lineno":   line,
+			"in_app":   !strings.HasPrefix(function, "runtime.") && !strings.HasPrefix(function, "testing."),
+		})
+	}
+
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames
+}
+
+// sentryLevel returns the Sentry level of the level.
+func sentryLevel(level Level) string {
+	switch level {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warning"
+	case LevelError:
+		return "error"
+	}
+	return "fatal"
+}
+
+// newEventID returns a new random Sentry event id.
+func newEventID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,108 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestSentryHook returns a Sentry hook posting to a local server and the events received.
+func newTestSentryHook(t *testing.T, config SentryConfig) (*SentryHook, chan map[string]interface{}) {
+	events := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/42/store/" || !strings.Contains(r.Header.Get("X-Sentry-Auth"), "sentry_key=public") {
+			t.Errorf("unexpected request %s %q", r.URL.Path, r.Header.Get("X-Sentry-Auth"))
+		}
+		event := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		events <- event
+	}))
+	t.Cleanup(server.Close)
+
+	config.DSN = strings.Replace(server.URL, "://", "://public@", 1) + "/42"
+	hook, err := NewSentryHook(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hook, events
+}
+
+func TestSentryHookFire(t *testing.T) {
+	hook, events := newTestSentryHook(t, SentryConfig{Environment: "test"})
+	defer hook.Close()
+
+	err := hook.Fire(HookEntry{
+		Level:      LevelError,
+		Time:       time.Now(),
+		Message:    "failed",
+		LoggerName: "api",
+		Stack:      "main.handler\n\t/app/main.go:10\nmain.main\n\t/app/main.go:5",
+		Fields:     Fields{"error": "boom", "error_type": "*errors.errorString", RequestIDKey: "abc", "order": 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hook.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	event := <-events
+	if event["message"] != "failed" || event["level"] != "error" || event["environment"] != "test" {
+		t.Fatalf("unexpected event %v", event)
+	}
+	if tags := event["tags"].(map[string]interface{}); tags[RequestIDKey] != "abc" {
+		t.Fatalf("unexpected tags %v", tags)
+	}
+	exception := event["exception"].(map[string]interface{})["values"].([]interface{})[0].(map[string]interface{})
+	if exception["type"] != "*errors.errorString" || exception["value"] != "boom" {
+		t.Fatalf("unexpected exception %v", exception)
+	}
+	frames := exception["stacktrace"].(map[string]interface{})["frames"].([]interface{})
+	if len(frames) != 2 || frames[0].(map[string]interface{})["function"] != "main.main" {
+		t.Fatalf("expected the frames from the oldest call, got %v", frames)
+	}
+}
+
+func TestSentryHookDedup(t *testing.T) {
+	hook, events := newTestSentryHook(t, SentryConfig{DedupWindow: time.Minute})
+	defer hook.Close()
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := hook.Fire(HookEntry{Level: LevelError, Time: now, Message: "failed"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := hook.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+}
+
+func TestSentryHookFireAfterClose(t *testing.T) {
+	hook, events := newTestSentryHook(t, SentryConfig{})
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	before := Dropped()
+	if err := hook.Fire(HookEntry{Level: LevelError, Time: time.Now(), Message: "failed"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := hook.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected the event to be dropped, got %d", len(events))
+	}
+	if after := Dropped(); after.Sentry-before.Sentry != 1 || after.Overflow != before.Overflow {
+		t.Fatalf("expected 1 event dropped by the Sentry hook, got %+v then %+v", before, after)
+	}
+}
@@ -0,0 +1,408 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewSlog returns a slog.Logger writing through the logger.
+func NewSlog(logger Logger) *slog.Logger {
+	return slog.New(NewSlogHandler(logger))
+}
+
+// NewSlogHandler returns a slog.Handler writing through the logger, so log/slog entries
+// share its outputs, fields and level.
+func NewSlogHandler(logger Logger) slog.Handler {
+	switch l := logger.(type) {
+	case *SlogLogger:
+		return l.handler
+	case *ZapLogger:
+		return &slogHandler{core: l.Logger.Core(), name: l.name}
+	}
+	return &slogHandler{logger: logger}
+}
+
+// slogHandler is a slog.Handler writing to a zap core, or to a Logger if it has no core.
+type slogHandler struct {
+	core   zapcore.Core
+	logger Logger
+	name   string
+	fields []zapcore.Field
+}
+
+// Enabled returns true if the level is enabled.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if h.core == nil {
+		return true
+	}
+	return h.core.Enabled(zapLevelFromSlog(level))
+}
+
+// Handle writes the record with the trace, baggage and request fields of the context,
+// except those already carried by the logger stored with ContextForRequest.
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	requestFields, traceApplied := unappliedRequestFields(ctx, requestFields(ctx))
+	fields := make([]zapcore.Field, 0, record.NumAttrs()+len(requestFields)+2)
+	for key, value := range requestFields {
+		fields = append(fields, zap.Any(key, value))
+	}
+	if traceID, spanID, ok := TraceContext(ctx); ok && !traceApplied {
+		fields = append(fields, zap.String(TraceIDKey, traceID), zap.String(SpanIDKey, spanID))
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendSlogAttr(fields, attr)
+		return true
+	})
+
+	level := zapLevelFromSlog(record.Level)
+	if h.core == nil {
+		logged := make([]Field, 0, len(h.fields)+len(fields))
+		for _, field := range append(append([]zapcore.Field{}, h.fields...), fields...) {
+			logged = append(logged, ZapField(field))
+		}
+		logAt(h.logger, level, record.Message, logged...)
+		return nil
+	}
+
+	entry := zapcore.Entry{
+		Level:      level,
+		Time:       record.Time,
+		LoggerName: h.name,
+		Message:    record.Message,
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		entry.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+
+	if checked := h.core.Check(entry, nil); checked != nil {
+		checked.Write(fields...)
+	}
+	return nil
+}
+
+// WithAttrs returns a handler adding the attributes to every record.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]zapcore.Field, 0, len(attrs))
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, attr)
+	}
+	return h.with(fields)
+}
+
+// WithGroup returns a handler nesting the following attributes under the group.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with([]zapcore.Field{zap.Namespace(name)})
+}
+
+// with returns a handler adding the fields to every record.
+func (h *slogHandler) with(fields []zapcore.Field) slog.Handler {
+	clone := *h
+	if h.core != nil {
+		clone.core = h.core.With(fields)
+	} else {
+		clone.fields = append(append([]zapcore.Field{}, h.fields...), fields...)
+	}
+	return &clone
+}
+
+// appendSlogAttr appends the attribute to the fields as a zap field.
+func appendSlogAttr(fields []zapcore.Field, attr slog.Attr) []zapcore.Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		if attr.Key == "" {
+			for _, groupAttr := range group {
+				fields = appendSlogAttr(fields, groupAttr)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(attr.Key, zapcore.ObjectMarshalerFunc(func(encoder zapcore.ObjectEncoder) error {
+			for _, field := range appendSlogAttrs(nil, group) {
+				field.AddTo(encoder)
+			}
+			return nil
+		})))
+	case slog.KindString:
+		return append(fields, zap.String(attr.Key, attr.Value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(attr.Key, attr.Value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(attr.Key, attr.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(attr.Key, attr.Value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(attr.Key, attr.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(attr.Key, attr.Value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(attr.Key, attr.Value.Time()))
+	}
+
+	if err, ok := attr.Value.Any().(error); ok {
+		return append(fields, zapFields(ErrorFields(attr.Key, err))...)
+	}
+	return append(fields, zap.Any(attr.Key, attr.Value.Any()))
+}
+
+// appendSlogAttrs appends the attributes to the fields as zap fields.
+func appendSlogAttrs(fields []zapcore.Field, attrs []slog.Attr) []zapcore.Field {
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, attr)
+	}
+	return fields
+}
+
+// zapLevelFromSlog converts a slog.Level to the nearest zapcore.Level below it.
+func zapLevelFromSlog(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	}
+	return zapcore.ErrorLevel
+}
+
+// slogLevelFromZap converts a zapcore.Level to a slog.Level.
+func slogLevelFromZap(level zapcore.Level) slog.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return slog.LevelDebug
+	case zapcore.InfoLevel:
+		return slog.LevelInfo
+	case zapcore.WarnLevel:
+		return slog.LevelWarn
+	}
+	return slog.LevelError + slog.Level(level-zapcore.ErrorLevel)
+}
+
+// logAt logs the message at the level with the logger.
+func logAt(logger Logger, level zapcore.Level, message string, fields ...Field) {
+	switch level {
+	case zapcore.DebugLevel:
+		logger.Debug(message, fields...)
+	case zapcore.InfoLevel:
+		logger.Info(message, fields...)
+	case zapcore.WarnLevel:
+		logger.Warn(message, fields...)
+	default:
+		logger.Error(message, fields...)
+	}
+}
+
+// SlogLogger is a Logger writing to a slog.Handler.
+type SlogLogger struct {
+	handler slog.Handler
+	level   slog.Leveler
+	name    string
+}
+
+// NewSlogLogger creates a new Logger writing to the slog.Handler.
+func NewSlogLogger(handler slog.Handler) Logger {
+	return &SlogLogger{handler: handler}
+}
+
+// Handler returns the slog.Handler of the logger.
+func (l *SlogLogger) Handler() slog.Handler {
+	return l.handler
+}
+
+// WithLevel returns the logger at the supplied level.
+func (l *SlogLogger) WithLevel(level Level) Logger {
+	zapLevel, err := level.zapLevel()
+	if err != nil {
+		panic(err)
+	}
+	clone := *l
+	clone.level = slogLevelFromZap(zapLevel)
+	return &clone
+}
+
+// WithField returns the logger with the supplied field.
+func (l *SlogLogger) WithField(key string, value interface{}) Logger {
+	return l.with(slog.Any(key, value))
+}
+
+// WithFields returns the logger with the supplied fields.
+func (l *SlogLogger) WithFields(fields Fields) Logger {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for key, value := range fields {
+		attrs = append(attrs, slog.Any(key, value))
+	}
+	return l.with(attrs...)
+}
+
+// WithError returns the logger with the supplied error.
+func (l *SlogLogger) WithError(err error) Logger {
+	return l.With(Err(err))
+}
+
+// With returns the logger with the supplied fields.
+func (l *SlogLogger) With(fields ...Field) Logger {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		attrs = appendFieldAttr(attrs, field)
+	}
+	return l.with(attrs...)
+}
+
+// Named returns the logger with the supplied name appended to its name.
+func (l *SlogLogger) Named(name string) Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	clone.name = name
+	return &clone
+}
+
+// Enabled returns true if the logger writes entries at the level, so expensive
+// messages and fields can be skipped when it does not.
+func (l *SlogLogger) Enabled(level Level) bool {
+	zapLevel, err := level.zapLevel()
+	return err == nil && l.enabled(slogLevelFromZap(zapLevel))
+}
+
+// Debug logs a message at debug level.
+func (l *SlogLogger) Debug(message string, fields ...Field) {
+	l.log(slog.LevelDebug, message, fields)
+}
+
+// Info logs a message at info level.
+func (l *SlogLogger) Info(message string, fields ...Field) {
+	l.log(slog.LevelInfo, message, fields)
+}
+
+// Warn logs a message at warn level.
+func (l *SlogLogger) Warn(message string, fields ...Field) {
+	l.log(slog.LevelWarn, message, fields)
+}
+
+// Error logs a message at error level.
+func (l *SlogLogger) Error(message string, fields ...Field) {
+	l.log(slog.LevelError, message, fields)
+}
+
+// Fatal logs a message at error level and exits the process.
+func (l *SlogLogger) Fatal(message string, fields ...Field) {
+	l.log(slogLevelFromZap(zapcore.FatalLevel), message, fields)
+	os.Exit(1)
+}
+
+// Panic logs a message at error level and panics.
+func (l *SlogLogger) Panic(message string, fields ...Field) {
+	l.log(slogLevelFromZap(zapcore.PanicLevel), message, fields)
+	panic(message)
+}
+
+// Debugf formats and logs a message at debug level, formatting only if the level is enabled.
+func (l *SlogLogger) Debugf(format string, args ...interface{}) {
+	if l.enabled(slog.LevelDebug) {
+		l.log(slog.LevelDebug, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Infof formats and logs a message at info level, formatting only if the level is enabled.
+func (l *SlogLogger) Infof(format string, args ...interface{}) {
+	if l.enabled(slog.LevelInfo) {
+		l.log(slog.LevelInfo, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Warnf formats and logs a message at warn level, formatting only if the level is enabled.
+func (l *SlogLogger) Warnf(format string, args ...interface{}) {
+	if l.enabled(slog.LevelWarn) {
+		l.log(slog.LevelWarn, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Errorf formats and logs a message at error level, formatting only if the level is enabled.
+func (l *SlogLogger) Errorf(format string, args ...interface{}) {
+	if l.enabled(slog.LevelError) {
+		l.log(slog.LevelError, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Fatalf formats and logs a message at error level and exits the process.
+func (l *SlogLogger) Fatalf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	l.log(slogLevelFromZap(zapcore.FatalLevel), message, nil)
+	os.Exit(1)
+}
+
+// Panicf formats and logs a message at error level and panics.
+func (l *SlogLogger) Panicf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	l.log(slogLevelFromZap(zapcore.PanicLevel), message, nil)
+	panic(message)
+}
+
+// enabled returns true if the level is enabled by the logger and its handler.
+func (l *SlogLogger) enabled(level slog.Level) bool {
+	if l.level != nil && level < l.level.Level() {
+		return false
+	}
+	return l.handler.Enabled(context.Background(), level)
+}
+
+// with returns the logger with the supplied attributes.
+func (l *SlogLogger) with(attrs ...slog.Attr) Logger {
+	clone := *l
+	clone.handler = l.handler.WithAttrs(attrs)
+	return &clone
+}
+
+// log writes a record to the handler if the level is enabled.
+func (l *SlogLogger) log(level slog.Level, message string, fields []Field) {
+	if !l.enabled(level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	record := slog.NewRecord(time.Now(), level, message, pcs[0])
+	if l.name != "" {
+		record.AddAttrs(slog.String("name", l.name))
+	}
+
+	for _, field := range fields {
+		record.AddAttrs(appendFieldAttr(nil, field)...)
+	}
+
+	_ = l.handler.Handle(context.Background(), record)
+}
+
+// appendFieldAttr appends the field to the attributes, expanding errors into their fields.
+func appendFieldAttr(attrs []slog.Attr, field Field) []slog.Attr {
+	if err, ok := field.Value.(error); ok {
+		for _, errField := range ErrorFields(field.Key, err) {
+			attrs = appendFieldAttr(attrs, errField)
+		}
+		return attrs
+	}
+	return append(attrs, slog.Any(field.Key, field.Resolved()))
+}
@@ -0,0 +1,50 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandlerRequestFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		forRequest bool
+	}{
+		{"plain logger", false},
+		{"request logger", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			logger := NewZapLogger(&Config{LogLevel: "info", LogFormat: "json", File: FileConfig{Path: path}})
+			defer logger.(*ZapLogger).Close()
+
+			ctx := ContextWithBaggage(ContextWithRequestID(context.Background(), "abc"), map[string]string{"tenant": "acme"})
+			ctx = ContextWithLogger(ctx, logger)
+			if test.forRequest {
+				ctx = ContextForRequest(ctx)
+			}
+			slog.New(NewSlogHandler(ContextLogger(ctx))).InfoContext(ctx, "created")
+			if err := logger.(*ZapLogger).Sync(); err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			line := string(content)
+			if count := strings.Count(line, `"request_id":"abc"`); count != 1 {
+				t.Fatalf("expected the request id once, got %d in %q", count, line)
+			}
+			if count := strings.Count(line, `"tenant":"acme"`); count != 1 {
+				t.Fatalf("expected the baggage once, got %d in %q", count, line)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package log
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SyslogFacility is a syslog facility.
+type SyslogFacility int
+
+const (
+	// FacilityUser is the user-level messages facility.
+	FacilityUser SyslogFacility = 1
+	// FacilityDaemon is the system daemons facility.
+	FacilityDaemon SyslogFacility = 3
+	// FacilityLocal0 is the first of the local use facilities, local0 to local7.
+	FacilityLocal0 SyslogFacility = 16
+)
+
+// syslogTimeout is the timeout to connect and write to the syslog server
+const syslogTimeout = 5 * time.Second
+
+// SyslogConfig is the configuration for the RFC 5424 syslog sink.
+type SyslogConfig struct {
+	// Network is udp, tcp or tls. TCP and TLS messages are framed with octet counting (RFC 6587).
+	Network string
+	// Address is the host:port of the syslog server. The syslog sink is disabled when empty.
+	Address string
+	// Facility is the syslog facility, user by default.
+	Facility SyslogFacility
+	// AppName is the APP-NAME of the messages, the program name by default.
+	AppName string
+	// Hostname is the HOSTNAME of the messages, the host name by default.
+	Hostname string
+	// TLSConfig is the TLS configuration of the tls network.
+	TLSConfig *tls.Config
+}
+
+// Enabled returns true if the syslog sink is configured.
+func (c SyslogConfig) Enabled() bool {
+	return c.Address != ""
+}
+
+// SyslogSink is a zapcore.WriteSyncer sending entries to a syslog server as RFC 5424 messages,
+// with the encoded entry as the message.
+type SyslogSink struct {
+	config   SyslogConfig
+	appName  string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink creates a new SyslogSink. It connects to the syslog server on the first write,
+// and reconnects after a failed write.
+func NewSyslogSink(config SyslogConfig) (*SyslogSink, error) {
+	switch config.Network {
+	case "":
+		config.Network = "udp"
+	case "udp", "tcp", "tls":
+	default:
+		return nil, fmt.Errorf("invalid syslog network %q", config.Network)
+	}
+	if config.Facility == 0 {
+		config.Facility = FacilityUser
+	}
+	if config.Facility < 0 || config.Facility > 23 {
+		return nil, fmt.Errorf("invalid syslog facility %d", config.Facility)
+	}
+
+	sink := &SyslogSink{config: config, appName: config.AppName, hostname: config.Hostname}
+	if sink.appName == "" {
+		sink.appName = filepath.Base(os.Args[0])
+	}
+	if sink.hostname == "" {
+		sink.hostname, _ = os.Hostname()
+	}
+	return sink, nil
+}
+
+// Write sends each encoded entry as a syslog message, reconnecting once if the connection failed.
+func (s *SyslogSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range parseSinkEntries(p) {
+		message := s.format(entry)
+		if err := s.send(message); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Sync is a no-op as messages are sent when written.
+func (s *SyslogSink) Sync() error {
+	return nil
+}
+
+// Close closes the connection to the syslog server.
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// format formats the entry as an RFC 5424 message.
+func (s *SyslogSink) format(entry sinkEntry) []byte {
+	priority := int(s.config.Facility)*8 + syslogSeverity(entry.level)
+	header := fmt.Sprintf("<%d>1 %s %s %s %d - - ",
+		priority,
+		entry.time.UTC().Format(time.RFC3339Nano),
+		syslogHeaderField(s.hostname, 255),
+		syslogHeaderField(s.appName, 48),
+		os.Getpid(),
+	)
+
+	message := append([]byte(header), entry.line...)
+	if s.config.Network == "udp" {
+		return message
+	}
+	return append([]byte(strconv.Itoa(len(message))+" "), message...)
+}
+
+// send sends the message, reconnecting and retrying once on error.
+func (s *SyslogSink) send(message []byte) error {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			if err = s.connect(); err != nil {
+				continue
+			}
+		}
+		_ = s.conn.SetWriteDeadline(time.Now().Add(syslogTimeout))
+		if _, err = s.conn.Write(message); err == nil {
+			return nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	return fmt.Errorf("failed to send the syslog message, %w", err)
+}
+
+// connect connects to the syslog server.
+func (s *SyslogSink) connect() error {
+	dialer := &net.Dialer{Timeout: syslogTimeout}
+
+	var conn net.Conn
+	var err error
+	if s.config.Network == "tls" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.config.Address, s.config.TLSConfig)
+	} else {
+		conn, err = dialer.Dial(s.config.Network, s.config.Address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to the syslog server %s, %w", s.config.Address, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// syslogHeaderField returns the value as a header field, printable ASCII of the maximum length or "-".
+func syslogHeaderField(value string, maxLength int) string {
+	field := make([]byte, 0, len(value))
+	for i := 0; i < len(value) && len(field) < maxLength; i++ {
+		if value[i] >= 0x21 && value[i] <= 0x7e {
+			field = append(field, value[i])
+		}
+	}
+	if len(field) == 0 {
+		return "-"
+	}
+	return string(field)
+}
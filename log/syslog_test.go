@@ -0,0 +1,127 @@
+package log
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testEntry = `{"level":"ERROR","time":"2024-03-01T10:00:00.000Z","message":"failed"}` + "\n"
+
+func TestSyslogSinkUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewSyslogSink(SyslogConfig{Address: conn.LocalAddr().String(), AppName: "app", Hostname: "host"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if _, err := sink.Write([]byte(testEntry)); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// user facility and error severity
+	expected := "<11>1 2024-03-01T10:00:00Z host app "
+	if message := string(buf[:n]); !strings.HasPrefix(message, expected) || !strings.HasSuffix(message, strings.TrimSpace(testEntry)) {
+		t.Fatalf("unexpected syslog message %q", message)
+	}
+}
+
+func TestSyslogSinkTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	messages := make(chan string, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go readFramedMessages(conn, messages)
+		}
+	}()
+
+	sink, err := NewSyslogSink(SyslogConfig{Network: "tcp", Address: listener.Addr().String(), Facility: FacilityLocal0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := sink.Write([]byte(testEntry)); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case message := <-messages:
+			if !strings.HasPrefix(message, "<131>1 ") {
+				t.Fatalf("unexpected syslog message %q", message)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the syslog message")
+		}
+		// the sink reconnects after a write on a broken connection fails
+		sink.mu.Lock()
+		_ = sink.conn.Close()
+		sink.mu.Unlock()
+	}
+}
+
+func TestSyslogSinkConnectsLazily(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	sink, err := NewSyslogSink(SyslogConfig{Network: "tcp", Address: address})
+	if err != nil {
+		t.Fatalf("expected the sink to be created without a server, got %v", err)
+	}
+	defer sink.Close()
+
+	if _, err := sink.Write([]byte(testEntry)); err == nil {
+		t.Fatal("expected the write to fail without a server")
+	}
+}
+
+// readFramedMessages reads the octet counted messages of the connection.
+func readFramedMessages(conn net.Conn, messages chan<- string) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		length, err := reader.ReadString(' ')
+		if err != nil {
+			return
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(length))
+		if err != nil {
+			return
+		}
+		message := make([]byte, size)
+		if _, err := io.ReadFull(reader, message); err != nil {
+			return
+		}
+		messages <- string(message)
+	}
+}
@@ -0,0 +1,159 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// TraceparentHeaderName is the W3C trace context header name
+	TraceparentHeaderName = "traceparent"
+	// TraceIDKey is the log field holding the trace id
+	TraceIDKey = "trace_id"
+	// SpanIDKey is the log field holding the span id
+	SpanIDKey = "span_id"
+)
+
+// spanEvents determines whether all ForRequest loggers also record entries as span events
+var spanEvents atomic.Bool
+
+// SetSpanEvents sets whether the loggers returned by ForRequest record their entries as events
+// of the OpenTelemetry span in the context, whatever the SpanEvents setting of their Config.
+// Loggers with SpanEvents configured record span events either way.
+func SetSpanEvents(enabled bool) {
+	spanEvents.Store(enabled)
+}
+
+// ContextWithTraceparent returns a new context with the W3C traceparent header value.
+// It is used to correlate logs with traces when no OpenTelemetry SDK is present.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	return context.WithValue(ctx, traceparentContextKey, traceparent)
+}
+
+// TraceContext returns the trace id and span id of the context.
+// The OpenTelemetry span in the context takes precedence over a W3C traceparent stored
+// with ContextWithTraceparent or available as a request header of the context.
+func TraceContext(ctx context.Context) (traceID string, spanID string, ok bool) {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		return spanContext.TraceID().String(), spanContext.SpanID().String(), true
+	}
+
+	traceparent, _ := ctx.Value(traceparentContextKey).(string)
+	if traceparent == "" {
+		if headers, isHeaders := ctx.(interface{ GetHeader(key string) string }); isHeaders {
+			traceparent = headers.GetHeader(TraceparentHeaderName)
+		}
+	}
+	return parseTraceparent(traceparent)
+}
+
+// parseTraceparent parses the trace id and parent id of a W3C traceparent header value.
+func parseTraceparent(traceparent string) (string, string, bool) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", "", false
+	}
+
+	traceID, err := trace.TraceIDFromHex(parts[1])
+	if err != nil {
+		return "", "", false
+	}
+	spanID, err := trace.SpanIDFromHex(parts[2])
+	if err != nil {
+		return "", "", false
+	}
+	return traceID.String(), spanID.String(), true
+}
+
+// withTrace returns the logger with the trace fields of the context and, if enabled,
+// recording its entries as events of the span in the context.
+func withTrace(ctx context.Context, logger Logger) Logger {
+	if traceID, spanID, ok := TraceContext(ctx); ok {
+		logger = logger.WithField(TraceIDKey, traceID).WithField(SpanIDKey, spanID)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	zapLogger, ok := logger.(*ZapLogger)
+	if !ok || !(spanEvents.Load() || zapLogger.scope.spanEvents) || !span.IsRecording() {
+		return logger
+	}
+
+	// the span is passed down the core chain, so the events are recorded behind the redaction
+	// and the sampling; the field is skipped by the encoders
+	newLogger := zapLogger.Logger.With(zapcore.Field{Key: spanFieldKey, Type: zapcore.SkipType, Interface: spanField{span}})
+	return &ZapLogger{zapLogger.config, zapLogger.name, zapLogger.sinks, zapLogger.scope, newLogger}
+}
+
+// spanFieldKey is the key of the field carrying the span of the span events
+const spanFieldKey = "span"
+
+// spanField is the value of the field carrying the span of the span events
+type spanField struct {
+	span trace.Span
+}
+
+// spanEventCore records entries as events of the span added to the logger by withTrace.
+// Without a span it records nothing.
+type spanEventCore struct {
+	span   trace.Span
+	fields []zapcore.Field
+}
+
+// Enabled returns true as the levels are enabled by the outer cores.
+func (c *spanEventCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With returns a core recording the fields with every event, and recording the events on the
+// span of the fields if any.
+func (c *spanEventCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append([]zapcore.Field{}, c.fields...)
+	for _, field := range fields {
+		if value, ok := field.Interface.(spanField); ok && field.Type == zapcore.SkipType {
+			clone.span = value.span
+			continue
+		}
+		clone.fields = append(clone.fields, field)
+	}
+	return &clone
+}
+
+// Check adds the core to the checked entry if it has a recording span.
+func (c *spanEventCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.span != nil && c.span.IsRecording() {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write adds the entry as an event to the span.
+func (c *spanEventCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(encoder)
+	}
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+
+	attributes := make([]attribute.KeyValue, 0, len(encoder.Fields)+1)
+	attributes = append(attributes, attribute.String("log.severity", entry.Level.CapitalString()))
+	for key, value := range encoder.Fields {
+		attributes = append(attributes, attribute.String(key, fmt.Sprint(value)))
+	}
+
+	c.span.AddEvent(entry.Message, trace.WithAttributes(attributes...), trace.WithTimestamp(entry.Time))
+	return nil
+}
+
+// Sync is a no-op as span events are exported with the span.
+func (c *spanEventCore) Sync() error {
+	return nil
+}
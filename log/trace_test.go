@@ -0,0 +1,112 @@
+package log
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingSpan is a recording span keeping its events.
+type recordingSpan struct {
+	trace.Span
+
+	mu     sync.Mutex
+	events []recordedEvent
+}
+
+// recordedEvent is an event added to a recordingSpan.
+type recordedEvent struct {
+	name       string
+	attributes map[string]string
+}
+
+func (s *recordingSpan) IsRecording() bool {
+	return true
+}
+
+func (s *recordingSpan) AddEvent(name string, options ...trace.EventOption) {
+	attributes := map[string]string{}
+	config := trace.NewEventConfig(options...)
+	for _, attr := range config.Attributes() {
+		attributes[string(attr.Key)] = attr.Value.Emit()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, recordedEvent{name, attributes})
+}
+
+// newSpanContext returns a context with a recording span.
+func newSpanContext() (context.Context, *recordingSpan) {
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	span := &recordingSpan{Span: trace.SpanFromContext(trace.ContextWithSpanContext(context.Background(), spanContext))}
+	return trace.ContextWithSpan(context.Background(), span), span
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		traceID     string
+		spanID      string
+		ok          bool
+	}{
+		{"valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true},
+		{"invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "", "", false},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "", "", false},
+		{"missing parts", "00-4bf92f3577b34da6a3ce929d0e0e4736", "", "", false},
+		{"empty", "", "", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			traceID, spanID, ok := parseTraceparent(test.traceparent)
+			if traceID != test.traceID || spanID != test.spanID || ok != test.ok {
+				t.Fatalf("got %q %q %t", traceID, spanID, ok)
+			}
+		})
+	}
+}
+
+func TestSpanEventsAreRedactedAndSampled(t *testing.T) {
+	logger := NewZapLogger(&Config{
+		LogLevel:   "info",
+		LogFormat:  "json",
+		SpanEvents: true,
+		File:       FileConfig{Path: filepath.Join(t.TempDir(), "app.log")},
+		Redaction:  RedactionConfig{Enabled: true},
+		Sampling:   SamplingConfig{Initial: 1, Thereafter: 100, Interval: time.Minute},
+	})
+	defer logger.(*ZapLogger).Close()
+
+	ctx, span := newSpanContext()
+	requestLogger := ForRequest(ContextWithLogger(ctx, logger))
+	for i := 0; i < 3; i++ {
+		requestLogger.WithField("password", "hunter2").Info("signed in")
+	}
+	requestLogger.Debug("below the level")
+
+	span.mu.Lock()
+	defer span.mu.Unlock()
+	if len(span.events) != 1 {
+		t.Fatalf("expected 1 span event, got %d", len(span.events))
+	}
+	event := span.events[0]
+	if event.name != "signed in" || event.attributes["password"] != redactedPlaceholder {
+		t.Fatalf("unexpected span event %+v", event)
+	}
+	if event.attributes[TraceIDKey] != (trace.TraceID{1}).String() {
+		t.Fatalf("expected the trace id attribute, got %+v", event.attributes)
+	}
+	if _, ok := event.attributes[spanFieldKey]; ok {
+		t.Fatalf("the span field must not be an attribute, got %+v", event.attributes)
+	}
+}
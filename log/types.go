@@ -1,9 +1,5 @@
 package log
 
-import (
-	"go.uber.org/zap/zapcore"
-)
-
 // Level is the log level
 type Level string
 
@@ -29,20 +25,40 @@ type LoggerConfig interface {
 	Format() string
 	SourceProgram() string
 	IsDev() bool
+	NamedLogLevels() string
+	TraceSpanEvents() bool
+	FileSink() FileConfig
+	SamplingPolicy() SamplingConfig
+	RedactionPolicy() RedactionConfig
+	AsyncPolicy() AsyncConfig
+	SyslogSink() SyslogConfig
+	JournaldSink() JournaldConfig
+	HTTPSink() HTTPSinkConfig
 }
 
 // Logger is the interface for the logger.
+// It is independent of the logging backend, so alternative implementations can be
+// stored in the context or set as the global logger.
 type Logger interface {
 	WithLevel(level Level) Logger
 	WithField(key string, value interface{}) Logger
 	WithFields(fields Fields) Logger
 	WithError(err error) Logger
-	Debug(message string, args ...zapcore.Field)
-	Info(message string, args ...zapcore.Field)
-	Warn(message string, args ...zapcore.Field)
-	Error(message string, args ...zapcore.Field)
-	Fatal(message string, args ...zapcore.Field)
-	Panic(message string, args ...zapcore.Field)
+	With(fields ...Field) Logger
+	Named(name string) Logger
+	Enabled(level Level) bool
+	Debug(message string, fields ...Field)
+	Info(message string, fields ...Field)
+	Warn(message string, fields ...Field)
+	Error(message string, fields ...Field)
+	Fatal(message string, fields ...Field)
+	Panic(message string, fields ...Field)
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Panicf(format string, args ...interface{})
 }
 
 // NewLogger returns a new logger.
@@ -0,0 +1,52 @@
+package sapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/s3ndd/sen-go/log"
+)
+
+// AuditActorKey is the gin context key of the authenticated actor, set by the authentication middleware
+const AuditActorKey = "audit_actor"
+
+// RecordAudit records the audit event of the request with the global audit logger.
+// The actor and the request id are taken from the gin context when not set, and the method,
+// route and client ip are added to the details. The error must fail the request, as the
+// action cannot be audited.
+func RecordAudit(ctx *gin.Context, event log.AuditEvent) error {
+	if event.Actor == "" {
+		event.Actor = ctx.GetString(AuditActorKey)
+	}
+	if event.RequestID == "" {
+		event.RequestID = GetRequestID(ctx)
+	}
+
+	details := log.Fields{
+		"method":    ctx.Request.Method,
+		"route":     ctx.FullPath(),
+		"client_ip": ctx.ClientIP(),
+	}
+	for key, value := range event.Details {
+		details[key] = value
+	}
+	event.Details = details
+
+	if err := log.Audit().Record(ctx, event); err != nil {
+		log.ContextLogger(ctx).WithError(err).Error("Failed to record the audit event")
+		return NewPrivateError(err)
+	}
+	return nil
+}
+
+// AuditOutcomeForStatus returns the audit outcome of a response status code
+func AuditOutcomeForStatus(statusCode int) log.AuditOutcome {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return log.AuditDenied
+	case statusCode >= http.StatusBadRequest:
+		return log.AuditFailure
+	default:
+		return log.AuditSuccess
+	}
+}
@@ -0,0 +1,195 @@
+package sapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// defaultCatalog is the error catalog of the service
+var defaultCatalog = NewErrorCatalog()
+
+// NewErrorCatalog creates a new empty ErrorCatalog
+func NewErrorCatalog() *ErrorCatalog {
+	return &ErrorCatalog{definitions: map[int]ErrorDefinition{}}
+}
+
+// DefaultErrorCatalog returns the error catalog used by RegisterErrors and NewErrorFromCode
+func DefaultErrorCatalog() *ErrorCatalog {
+	return defaultCatalog
+}
+
+// RegisterErrors registers the error definitions in the default catalog.
+// It panics if a code is registered twice, so it is meant to be called from init or main.
+func RegisterErrors(definitions ...ErrorDefinition) {
+	defaultCatalog.MustRegister(definitions...)
+}
+
+// NewErrorFromCode returns the error of a code of the default catalog, see ErrorCatalog.NewError
+func NewErrorFromCode(code int, cause error) error {
+	return defaultCatalog.NewError(code, cause)
+}
+
+// NewValidationErrorFromCode returns the validation error of a code of the default catalog
+func NewValidationErrorFromCode(code int, fields ErrorField) ValidationError {
+	return defaultCatalog.NewValidationError(code, fields)
+}
+
+// IsRetryable returns true if the error has the code of a retryable definition of the default catalog
+func IsRetryable(err error) bool {
+	var codeErr ErrorCode
+	if !errors.As(err, &codeErr) {
+		return false
+	}
+	definition, ok := defaultCatalog.Lookup(codeErr.ErrorCode())
+	return ok && definition.Retryable
+}
+
+// Register registers the error definitions, returning an error if a code is invalid or already
+// registered. No definition is registered if an error is returned.
+func (c *ErrorCatalog) Register(definitions ...ErrorDefinition) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seen := make(map[int]bool, len(definitions))
+	for _, definition := range definitions {
+		if definition.Code <= 0 {
+			return fmt.Errorf("invalid error code %d, codes must be positive", definition.Code)
+		}
+		if definition.Status < http.StatusBadRequest || definition.Status > 599 {
+			return fmt.Errorf("invalid status %d of error code %d", definition.Status, definition.Code)
+		}
+		if existing, ok := c.definitions[definition.Code]; ok || seen[definition.Code] {
+			return fmt.Errorf("error code %d (%s) is already registered (%s)", definition.Code, definition.Name, existing.Name)
+		}
+		seen[definition.Code] = true
+	}
+
+	for _, definition := range definitions {
+		if definition.Message == "" {
+			definition.Message = http.StatusText(definition.Status)
+		}
+		c.definitions[definition.Code] = definition
+	}
+	return nil
+}
+
+// MustRegister registers the error definitions and panics on error
+func (c *ErrorCatalog) MustRegister(definitions ...ErrorDefinition) {
+	if err := c.Register(definitions...); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the definition of the code
+func (c *ErrorCatalog) Lookup(code int) (ErrorDefinition, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	definition, ok := c.definitions[code]
+	return definition, ok
+}
+
+// Definitions returns the definitions sorted by code
+func (c *ErrorCatalog) Definitions() []ErrorDefinition {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	definitions := make([]ErrorDefinition, 0, len(c.definitions))
+	for _, definition := range c.definitions {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Code < definitions[j].Code })
+	return definitions
+}
+
+// NewError returns the sapi error of the code: a PrivateError for 5xx statuses, an
+// APIResponseError otherwise. The response carries the message of the definition while the
+// cause, if any, is only logged. An unregistered code is returned as a PrivateError responding
+// with the status text only.
+func (c *ErrorCatalog) NewError(code int, cause error) error {
+	definition, ok := c.Lookup(code)
+	if !ok {
+		if cause == nil {
+			cause = fmt.Errorf("unregistered error code %d", code)
+		}
+		err := &catalogError{message: http.StatusText(http.StatusInternalServerError), cause: cause}
+		return NewPrivateError(err).WithErrorCode(code)
+	}
+
+	err := &catalogError{message: definition.Message, cause: cause}
+	if definition.Status >= http.StatusInternalServerError {
+		return NewPrivateErrorWithStatusCode(err, definition.Status).WithErrorCode(code)
+	}
+	return NewAPIResponseError(err, definition.Status).WithErrorCode(code)
+}
+
+// NewValidationError returns the validation error of the code with the invalid fields
+func (c *ErrorCatalog) NewValidationError(code int, fields ErrorField) ValidationError {
+	definition, ok := c.Lookup(code)
+	if !ok {
+		definition = ErrorDefinition{Status: http.StatusUnprocessableEntity, Message: http.StatusText(http.StatusUnprocessableEntity)}
+	}
+
+	err := NewValidationError(definition.Message, definition.Status).WithErrorCode(code)
+	if len(fields) > 0 {
+		err = err.WithErrorFields(fields)
+	}
+	return err
+}
+
+// WriteJSON writes the definitions sorted by code as a JSON array
+func (c *ErrorCatalog) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c.Definitions())
+}
+
+// WriteMarkdown writes the definitions sorted by code as a Markdown table
+func (c *ErrorCatalog) WriteMarkdown(w io.Writer) error {
+	var table strings.Builder
+	table.WriteString("| Code | Name | Status | Message | Retryable | Documentation |\n")
+	table.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, definition := range c.Definitions() {
+		retryable := "no"
+		if definition.Retryable {
+			retryable = "yes"
+		}
+		fmt.Fprintf(&table, "| %d | %s | %d %s | %s | %s | %s |\n",
+			definition.Code,
+			markdownCell(definition.Name),
+			definition.Status, http.StatusText(definition.Status),
+			markdownCell(definition.Message),
+			retryable,
+			markdownCell(definition.DocURL),
+		)
+	}
+
+	_, err := io.WriteString(w, table.String())
+	return err
+}
+
+// markdownCell escapes the text of a Markdown table cell
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\r", "", "\n", " ").Replace(text)
+}
+
+// catalogError is an error responding with the message of its definition and logging its cause
+type catalogError struct {
+	message string
+	cause   error
+}
+
+// Error returns the message of the definition
+func (e *catalogError) Error() string {
+	return e.message
+}
+
+// Unwrap returns the cause of the error
+func (e *catalogError) Unwrap() error {
+	return e.cause
+}
@@ -1,6 +1,10 @@
 package sapi
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/s3ndd/sen-go/log"
+)
 
 // NewAPIResponseError creates a new APIResponseError
 func NewAPIResponseError(err error, statusCode int) APIResponseError {
@@ -16,6 +20,17 @@ func (e APIResponseError) WithErrorCode(code int) APIResponseError {
 	return e
 }
 
+// WithMessageParams sets the parameters of the localized message for the APIResponseError
+func (e APIResponseError) WithMessageParams(params map[string]string) APIResponseError {
+	e.params = params
+	return e
+}
+
+// MessageParams returns the parameters of the localized message for the APIResponseError
+func (e APIResponseError) MessageParams() map[string]string {
+	return e.params
+}
+
 // ErrorCode returns the error code for the APIResponseError
 func (e APIResponseError) Error() string {
 	return e.err.Error()
@@ -31,6 +46,16 @@ func (e APIResponseError) StatusCode() int {
 	return e.statusCode
 }
 
+// Unwrap returns the underlying error of the APIResponseError
+func (e APIResponseError) Unwrap() error {
+	return e.err
+}
+
+// LogFields returns the log fields for the APIResponseError
+func (e APIResponseError) LogFields() log.Fields {
+	return errorLogFields(e.statusCode, e.errorCode)
+}
+
 // NewPrivateError creates a new PrivateError
 func NewPrivateError(err error) PrivateError {
 	return NewPrivateErrorWithStatusCode(err, http.StatusInternalServerError)
@@ -56,6 +81,17 @@ func (e PrivateError) WithErrorCode(code int) PrivateError {
 	return e
 }
 
+// WithMessageParams sets the parameters of the localized message for the PrivateError
+func (e PrivateError) WithMessageParams(params map[string]string) PrivateError {
+	e.params = params
+	return e
+}
+
+// MessageParams returns the parameters of the localized message for the PrivateError
+func (e PrivateError) MessageParams() map[string]string {
+	return e.params
+}
+
 // ErrorCode returns the error code for the PrivateError
 func (e PrivateError) ErrorCode() int {
 	return e.errorCode
@@ -71,6 +107,20 @@ func (e PrivateError) StatusCode() int {
 	return e.statusCode
 }
 
+// Unwrap returns the underlying error of the PrivateError
+func (e PrivateError) Unwrap() error {
+	return e.err
+}
+
+// LogFields returns the log fields for the PrivateError
+func (e PrivateError) LogFields() log.Fields {
+	fields := errorLogFields(e.statusCode, e.errorCode)
+	if e.message != "" {
+		fields["error_message"] = e.message
+	}
+	return fields
+}
+
 // NewValidationError creates a new ValidationError
 func NewValidationError(message string, statusCode int) ValidationError {
 	if statusCode == 0 {
@@ -96,6 +146,41 @@ func (e ValidationError) WithErrorFields(errorFields ErrorField) ValidationError
 	return e
 }
 
+// WithFieldMessage sets the message of an error field for the ValidationError, with the
+// translation key and the parameters of its localized message
+func (e ValidationError) WithFieldMessage(field, message, key string, params map[string]string) ValidationError {
+	fields := make(ErrorField, len(e.fields)+1)
+	for name, fieldMessage := range e.fields {
+		fields[name] = fieldMessage
+	}
+	fields[field] = message
+	e.fields = fields
+
+	fieldMessages := make(map[string]FieldMessage, len(e.fieldMessages)+1)
+	for name, fieldMessage := range e.fieldMessages {
+		fieldMessages[name] = fieldMessage
+	}
+	fieldMessages[field] = FieldMessage{Key: key, Params: params}
+	e.fieldMessages = fieldMessages
+	return e
+}
+
+// WithMessageParams sets the parameters of the localized message for the ValidationError
+func (e ValidationError) WithMessageParams(params map[string]string) ValidationError {
+	e.params = params
+	return e
+}
+
+// MessageParams returns the parameters of the localized message for the ValidationError
+func (e ValidationError) MessageParams() map[string]string {
+	return e.params
+}
+
+// FieldMessages returns the localizable messages of the error fields for the ValidationError
+func (e ValidationError) FieldMessages() map[string]FieldMessage {
+	return e.fieldMessages
+}
+
 // ErrorCode returns the error code for the ValidationError
 func (e ValidationError) ErrorCode() int {
 	return e.errorCode
@@ -115,3 +200,21 @@ func (e ValidationError) Error() string {
 func (e ValidationError) ErrorFields() ErrorField {
 	return e.fields
 }
+
+// LogFields returns the log fields for the ValidationError
+func (e ValidationError) LogFields() log.Fields {
+	fields := errorLogFields(e.statusCode, e.errorCode)
+	if len(e.fields) > 0 {
+		fields["validation_fields"] = e.fields
+	}
+	return fields
+}
+
+// errorLogFields returns the log fields for the status code and error code of an error
+func errorLogFields(statusCode int, errorCode int) log.Fields {
+	fields := log.Fields{"status_code": statusCode}
+	if errorCode != 0 {
+		fields["error_code"] = errorCode
+	}
+	return fields
+}
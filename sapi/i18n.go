@@ -0,0 +1,228 @@
+package sapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultTranslator is the translator of the error responses
+var defaultTranslator = NewTranslator("en")
+
+// NewTranslator creates a new Translator falling back to the default locale
+func NewTranslator(defaultLocale string) *Translator {
+	return &Translator{
+		defaultLocale: canonicalLocale(defaultLocale),
+		bundles:       map[string]map[string]string{},
+	}
+}
+
+// DefaultTranslator returns the translator used by RespondWithError
+func DefaultTranslator() *Translator {
+	return defaultTranslator
+}
+
+// LoadTranslations loads the message bundles of the directory in the default translator, see Translator.LoadDir
+func LoadTranslations(dir string) error {
+	return defaultTranslator.LoadDir(dir)
+}
+
+// AddMessages adds the message templates of the locale, keyed by translation key.
+// The messages of error codes are keyed error.<code>, e.g. error.1001, and the templates
+// may contain parameters, e.g. "User {name} was not found".
+func (t *Translator) AddMessages(locale string, messages map[string]string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	locale = canonicalLocale(locale)
+	bundle, ok := t.bundles[locale]
+	if !ok {
+		bundle = make(map[string]string, len(messages))
+		t.bundles[locale] = bundle
+	}
+	for key, message := range messages {
+		bundle[key] = message
+	}
+}
+
+// LoadFile loads a message bundle from a JSON object of message templates, named after its
+// locale, e.g. fr.json or pt-BR.json
+func (t *Translator) LoadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the message bundle %s, %w", path, err)
+	}
+
+	messages := map[string]string{}
+	if err := json.Unmarshal(content, &messages); err != nil {
+		return fmt.Errorf("failed to decode the message bundle %s, %w", path, err)
+	}
+	t.AddMessages(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), messages)
+	return nil
+}
+
+// LoadDir loads the message bundles of the JSON files of the directory
+func (t *Translator) LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list the message bundles of %s, %w", dir, err)
+	}
+	for _, path := range paths {
+		if err := t.LoadFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Locales returns the locales with a message bundle, sorted
+func (t *Translator) Locales() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	locales := make([]string, 0, len(t.bundles))
+	for locale := range t.bundles {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Negotiate returns the locale of the Accept-Language header value with a message bundle,
+// by preference, matching a region to its language, e.g. fr-CA to fr. It returns the default
+// locale if no locale matches.
+func (t *Translator) Negotiate(acceptLanguage string) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	best, bestQuality := t.defaultLocale, 0.0
+	for _, languageRange := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(languageRange), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= bestQuality || tag == "" || tag == "*" {
+			continue
+		}
+
+		locale := canonicalLocale(tag)
+		if _, ok := t.bundles[locale]; ok {
+			best, bestQuality = locale, quality
+			continue
+		}
+		language, _, _ := strings.Cut(locale, "-")
+		if _, ok := t.bundles[language]; ok {
+			best, bestQuality = language, quality
+		}
+	}
+	return best
+}
+
+// Translate returns the message of the key in the locale, or in the default locale if the
+// locale has no such message, with its parameters replaced. It returns false if there is no message.
+func (t *Translator) Translate(locale, key string, params map[string]string) (string, bool) {
+	message, _, ok := t.translate(canonicalLocale(locale), key, params)
+	return message, ok
+}
+
+// translate returns the message of the key and the locale it was found in
+func (t *Translator) translate(locale, key string, params map[string]string) (string, string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	template, ok := t.bundles[locale][key]
+	if !ok {
+		locale = t.defaultLocale
+		template, ok = t.bundles[locale][key]
+	}
+	if !ok {
+		return "", "", false
+	}
+
+	replacements := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(replacements...).Replace(template), locale, true
+}
+
+// empty returns true if the translator has no message bundle
+func (t *Translator) empty() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return len(t.bundles) == 0
+}
+
+// localizeErrorResponse translates the message and the field messages of the error response
+// to the locale negotiated from the Accept-Language header, and sets the Content-Language header
+func localizeErrorResponse(ctx *gin.Context, respErr error, response *ErrorResponse) {
+	if defaultTranslator.empty() {
+		return
+	}
+
+	locale := defaultTranslator.Negotiate(ctx.GetHeader("Accept-Language"))
+	contentLanguage := ""
+
+	if response.Code != 0 {
+		var params map[string]string
+		if paramsErr, ok := respErr.(MessageParams); ok {
+			params = paramsErr.MessageParams()
+		}
+		if message, used, ok := defaultTranslator.translate(locale, fmt.Sprintf("error.%d", response.Code), params); ok {
+			response.Error = message
+			contentLanguage = used
+		}
+	}
+
+	if messagesErr, ok := respErr.(FieldMessages); ok && len(messagesErr.FieldMessages()) > 0 {
+		fields := make(ErrorField, len(response.Fields))
+		for field, message := range response.Fields {
+			fields[field] = message
+		}
+		for field, fieldMessage := range messagesErr.FieldMessages() {
+			params := map[string]string{"field": field}
+			for name, value := range fieldMessage.Params {
+				params[name] = value
+			}
+			if message, used, ok := defaultTranslator.translate(locale, fieldMessage.Key, params); ok {
+				fields[field] = message
+				if contentLanguage == "" {
+					contentLanguage = used
+				}
+			}
+		}
+		response.Fields = fields
+	}
+
+	if contentLanguage != "" {
+		ctx.Header("Content-Language", contentLanguage)
+	}
+}
+
+// canonicalLocale returns the locale with a lowercase language and an uppercase region, e.g. pt-BR
+func canonicalLocale(locale string) string {
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '-' || r == '_' })
+	for i, part := range parts {
+		switch {
+		case i == 0 || len(part) > 4:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		default:
+			parts[i] = strings.ToUpper(part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,147 @@
+package sapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// useTestTranslator replaces the default translator with one translating to French for the test.
+func useTestTranslator(t *testing.T) *Translator {
+	translator := NewTranslator("en")
+	translator.AddMessages("en", map[string]string{
+		"error.1001":          "Order {id} was not found",
+		"validation.required": "{field} is required",
+	})
+	translator.AddMessages("fr", map[string]string{
+		"error.1001":          "La commande {id} est introuvable",
+		"validation.required": "{field} est obligatoire",
+	})
+	translator.AddMessages("pt-BR", map[string]string{
+		"error.1001": "Pedido {id} não encontrado",
+	})
+
+	previous := defaultTranslator
+	defaultTranslator = translator
+	t.Cleanup(func() { defaultTranslator = previous })
+	return translator
+}
+
+func TestTranslatorNegotiate(t *testing.T) {
+	translator := useTestTranslator(t)
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		expected       string
+	}{
+		{"empty", "", "en"},
+		{"exact", "fr", "fr"},
+		{"region to language", "fr-CA", "fr"},
+		{"region", "pt-br", "pt-BR"},
+		{"unknown", "de", "en"},
+		{"by preference", "de, fr;q=0.8, en;q=0.5", "fr"},
+		{"higher quality later", "en;q=0.3, fr;q=0.9", "fr"},
+		{"zero quality", "fr;q=0", "en"},
+		{"invalid quality", "fr;q=high, pt-BR;q=0.1", "pt-BR"},
+		{"wildcard", "*", "en"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if locale := translator.Negotiate(test.acceptLanguage); locale != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, locale)
+			}
+		})
+	}
+}
+
+func TestTranslatorLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "es.json"), []byte(`{"error.1001":"Pedido {id} no encontrado"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	translator := NewTranslator("en")
+	if err := translator.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if locales := translator.Locales(); !reflect.DeepEqual(locales, []string{"es"}) {
+		t.Fatalf("unexpected locales %v", locales)
+	}
+	if message, _ := translator.Translate("es", "error.1001", map[string]string{"id": "7"}); message != "Pedido 7 no encontrado" {
+		t.Fatalf("unexpected message %q", message)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "it.json"), []byte(`{`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := translator.LoadDir(dir); err == nil {
+		t.Fatal("expected an invalid bundle to fail")
+	}
+}
+
+func TestLocalizeErrorResponse(t *testing.T) {
+	useTestTranslator(t)
+
+	notFound := NewAPIResponseError(errors.New("order not found"), http.StatusNotFound).
+		WithErrorCode(1001).
+		WithMessageParams(map[string]string{"id": "42"})
+	invalid := NewValidationError("invalid request", http.StatusUnprocessableEntity).
+		WithFieldMessage("customer", "customer is required", "validation.required", nil).
+		WithFieldMessage("note", "note is too long", "validation.max", nil)
+
+	tests := []struct {
+		name            string
+		acceptLanguage  string
+		err             error
+		message         string
+		fields          ErrorField
+		contentLanguage string
+	}{
+		{"translated", "fr-FR", notFound, "La commande 42 est introuvable", nil, "fr"},
+		{"default locale", "de", notFound, "Order 42 was not found", nil, "en"},
+		{"no translation", "fr", NewAPIResponseError(errors.New("conflict"), http.StatusConflict).WithErrorCode(2002), "conflict", nil, ""},
+		{
+			"field messages",
+			"fr",
+			invalid,
+			"invalid request",
+			ErrorField{"customer": "customer est obligatoire", "note": "note is too long"},
+			"fr",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+			ctx.Request.Header.Set("Accept-Language", test.acceptLanguage)
+
+			response := ErrorResponse{Error: test.err.Error()}
+			if codeErr, ok := test.err.(ErrorCode); ok {
+				response.Code = codeErr.ErrorCode()
+			}
+			if fieldsErr, ok := test.err.(ErrorFields); ok {
+				response.Fields = fieldsErr.ErrorFields()
+			}
+			localizeErrorResponse(ctx, test.err, &response)
+
+			if response.Error != test.message {
+				t.Fatalf("expected the message %q, got %q", test.message, response.Error)
+			}
+			if test.fields != nil && !reflect.DeepEqual(response.Fields, test.fields) {
+				t.Fatalf("expected the fields %v, got %v", test.fields, response.Fields)
+			}
+			if language := recorder.Header().Get("Content-Language"); language != test.contentLanguage {
+				t.Fatalf("expected the content language %q, got %q", test.contentLanguage, language)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package sapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/s3ndd/sen-go/log"
+)
+
+// LogLevelHandler returns a handler reading (GET) and changing (PUT) the level of the controller.
+// A PUT request may supply a ttl after which the level reverts to the configured level.
+// Mount it on both methods, e.g. router.GET(path, handler) and router.PUT(path, handler).
+func LogLevelHandler(level *log.LevelController) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			if err := setLogLevel(ctx, level); err != nil {
+				RespondWithError(ctx, err)
+				return
+			}
+		default:
+			RespondWithError(ctx, NewAPIResponseError(
+				fmt.Errorf("method %s is not allowed", ctx.Request.Method),
+				http.StatusMethodNotAllowed,
+			))
+			return
+		}
+
+		RespondWithData(ctx, http.StatusOK, newLogLevelResponse(level))
+	}
+}
+
+// setLogLevel changes the level of the controller from the request body
+func setLogLevel(ctx *gin.Context, level *log.LevelController) error {
+	request := LogLevelRequest{}
+	if err := RequestBody(ctx, &request); err != nil {
+		return err
+	}
+
+	var ttl time.Duration
+	if request.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(request.TTL); err != nil || ttl < 0 {
+			return NewValidationError("invalid ttl", http.StatusBadRequest).
+				WithErrorFields(ErrorField{"ttl": "must be a positive duration such as 10m"})
+		}
+	}
+
+	if err := level.SetLevel(log.Level(strings.ToUpper(request.Level)), ttl); err != nil {
+		return NewValidationError(err.Error(), http.StatusBadRequest).
+			WithErrorFields(ErrorField{"level": "must be one of DEBUG, INFO, WARN, ERROR, FATAL, PANIC"})
+	}
+
+	return nil
+}
+
+// newLogLevelResponse creates a new LogLevelResponse from the controller
+func newLogLevelResponse(level *log.LevelController) LogLevelResponse {
+	response := LogLevelResponse{
+		Level:      level.Level().String(),
+		Configured: level.Configured().String(),
+	}
+	if revertAt := level.RevertAt(); !revertAt.IsZero() {
+		response.RevertAt = &revertAt
+	}
+	return response
+}
@@ -0,0 +1,12 @@
+package sapi
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/s3ndd/sen-go/log"
+)
+
+// MetricsHandler returns a handler serving the log entry counts in the Prometheus text format,
+// e.g. router.GET("/metrics/log", MetricsHandler())
+func MetricsHandler() gin.HandlerFunc {
+	return gin.WrapH(log.MetricsHandler())
+}
@@ -0,0 +1,111 @@
+package sapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/s3ndd/sen-go/log"
+)
+
+// RequestLogger returns a middleware assigning a request id to each request, storing a
+// request-scoped logger in the gin and request contexts, and logging one access line per request.
+// The request id is assigned as by the RequestID middleware, unless that middleware ran first.
+// Access lines are logged at info level, warn for 4xx and error for 5xx.
+func RequestLogger(config RequestLoggerConfig) gin.HandlerFunc {
+	skipPaths := make(map[string]bool, len(config.SkipPaths))
+	for _, path := range config.SkipPaths {
+		skipPaths[path] = true
+	}
+	skipRoutes := make(map[string]bool, len(config.SkipRoutes))
+	for _, route := range config.SkipRoutes {
+		skipRoutes[route] = true
+	}
+
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		assignRequestID(ctx, log.NewRequestID)
+
+		// the request context carries the span of the tracing middleware, the gin context does not
+		// fall back to it unless ContextWithFallback is set
+		requestCtx := ctx.Request.Context()
+		logger := config.Logger
+		if logger == nil {
+			logger = log.ContextLogger(requestCtx)
+		}
+		requestCtx = log.ContextForRequest(log.ContextWithLogger(requestCtx, logger))
+		logger = log.ContextLogger(requestCtx)
+		ctx.Set(log.LoggerKey, logger)
+		ctx.Set(log.RequestFieldsKey, log.RequestFieldsFromContext(requestCtx))
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+
+		ctx.Next()
+
+		if skipPaths[ctx.Request.URL.Path] || skipRoutes[ctx.FullPath()] || (config.Skip != nil && config.Skip(ctx)) {
+			return
+		}
+		logAccess(logger, ctx, time.Since(start))
+	}
+}
+
+// Baggage returns a middleware restoring the baggage fields of the W3C baggage header, so they
+// are added to the request loggers and propagated to downstream services by the client package.
+// Only the keys supplied are restored, none if no keys are supplied, and the request id is never
+// restored from the baggage. Use it before RequestLogger.
+func Baggage(keys ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		allowed[key] = key != log.RequestIDKey
+	}
+
+	return func(ctx *gin.Context) {
+		baggage := log.ParseBaggage(ctx.GetHeader(log.BaggageHeaderName))
+		for key := range baggage {
+			if !allowed[key] {
+				delete(baggage, key)
+			}
+		}
+		if len(baggage) > 0 {
+			setBaggage(ctx, baggage)
+		}
+		ctx.Next()
+	}
+}
+
+// setBaggage adds the baggage fields to both the request context and the gin context
+func setBaggage(ctx *gin.Context, baggage map[string]string) {
+	ctx.Request = ctx.Request.WithContext(log.ContextWithBaggage(ctx.Request.Context(), baggage))
+	ctx.Set(log.BaggageKey, log.BaggageFromContext(ctx.Request.Context()))
+}
+
+// logAccess logs the access line of the request at the level of its status class
+func logAccess(logger log.Logger, ctx *gin.Context, latency time.Duration) {
+	status := ctx.Writer.Status()
+	size := ctx.Writer.Size()
+	if size < 0 {
+		size = 0
+	}
+	fields := []log.Field{
+		log.String("method", ctx.Request.Method),
+		log.String("route", ctx.FullPath()),
+		log.String("path", ctx.Request.URL.Path),
+		log.Int("status", status),
+		log.Duration("latency", latency),
+		log.Int("bytes", size),
+		log.String("client_ip", ctx.ClientIP()),
+		log.String("user_agent", ctx.Request.UserAgent()),
+	}
+	if len(ctx.Errors) > 0 {
+		fields = append(fields, log.String("errors", ctx.Errors.String()))
+	}
+
+	switch {
+	case status >= http.StatusInternalServerError:
+		logger.Error("HTTP request", fields...)
+	case status >= http.StatusBadRequest:
+		logger.Warn("HTTP request", fields...)
+	default:
+		logger.Info("HTTP request", fields...)
+	}
+}
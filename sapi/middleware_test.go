@@ -0,0 +1,137 @@
+package sapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/s3ndd/sen-go/log"
+	"github.com/s3ndd/sen-go/log/logtest"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serve serves the request with the router and returns the response.
+func serve(router *gin.Engine, request *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRequestLogger(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		level  log.Level
+		logged bool
+	}{
+		{"ok", "/orders/1", http.StatusOK, log.LevelInfo, true},
+		{"client error", "/orders/2", http.StatusNotFound, log.LevelWarn, true},
+		{"server error", "/orders/3", http.StatusInternalServerError, log.LevelError, true},
+		{"skipped path", "/healthz", http.StatusOK, "", false},
+		{"skipped route", "/metrics/1", http.StatusOK, "", false},
+		{"skipped by function", "/orders/4?internal=true", http.StatusOK, "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger := logtest.New()
+			router := gin.New()
+			router.Use(RequestLogger(RequestLoggerConfig{
+				Logger:     logger,
+				SkipPaths:  []string{"/healthz"},
+				SkipRoutes: []string{"/metrics/:id"},
+				Skip:       func(ctx *gin.Context) bool { return ctx.Query("internal") == "true" },
+			}))
+			handler := func(ctx *gin.Context) {
+				log.ContextLogger(ctx).Info("handled")
+				ctx.Status(test.status)
+			}
+			router.GET("/orders/:id", handler)
+			router.GET("/metrics/:id", handler)
+			router.GET("/healthz", handler)
+
+			request := httptest.NewRequest(http.MethodGet, test.path, nil)
+			request.Header.Set(log.RequestIDHeaderName, "req-1")
+			serve(router, request)
+
+			// the handler logs with the request logger stored in the gin context
+			logger.RequireLogged(t, log.LevelInfo, "handled", log.String(log.RequestIDKey, "req-1"))
+
+			access := logger.FilterMessage("HTTP request")
+			if !test.logged {
+				if access.Len() != 0 {
+					t.Fatalf("expected no access line, got %v", access)
+				}
+				return
+			}
+			logger.RequireLogged(t, test.level, "HTTP request",
+				log.String(log.RequestIDKey, "req-1"),
+				log.String("method", http.MethodGet),
+				log.String("route", "/orders/:id"),
+				log.Int("status", test.status),
+			)
+		})
+	}
+}
+
+func TestRequestLoggerStoresTheRequestLogger(t *testing.T) {
+	logger := logtest.New()
+	router := gin.New()
+	router.Use(RequestLogger(RequestLoggerConfig{Logger: logger}))
+	router.GET("/orders", func(ctx *gin.Context) {
+		// the request context carries the logger and the applied request fields, so ForRequest
+		// does not add them again
+		log.ForRequest(ctx.Request.Context()).Info("from the request context")
+		log.ForRequest(ctx).Info("from the gin context")
+		ctx.Status(http.StatusOK)
+	})
+
+	serve(router, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	entries := logger.Entries().FilterMessageContains("from the")
+	if entries.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %v", entries)
+	}
+	for _, entry := range entries {
+		count := 0
+		for _, field := range entry.Fields {
+			if field.Key == log.RequestIDKey {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Fatalf("expected the request id once, got %v", entry.Fields)
+		}
+	}
+}
+
+func TestBaggage(t *testing.T) {
+	logger := logtest.New()
+	router := gin.New()
+	router.Use(Baggage("tenant"), RequestLogger(RequestLoggerConfig{Logger: logger}))
+	router.GET("/orders", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	request.Header.Set(log.BaggageHeaderName, "tenant=acme,user=alice,request_id=forged")
+	request.Header.Set(log.RequestIDHeaderName, "req-1")
+	serve(router, request)
+
+	entries := logger.FilterMessage("HTTP request")
+	if entries.Len() != 1 {
+		t.Fatalf("expected 1 access line, got %v", entries)
+	}
+	fields := entries[0].FieldMap()
+	if fields["tenant"] != "acme" || fields[log.RequestIDKey] != "req-1" {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+	if _, ok := fields["user"]; ok {
+		t.Fatalf("expected the keys not supplied to be ignored, got %v", fields)
+	}
+}
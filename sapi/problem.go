@@ -0,0 +1,83 @@
+package sapi
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorFormat is the format of the error responses.
+type ErrorFormat string
+
+const (
+	// ErrorFormatJSON renders the errors as an ErrorResponse, the default.
+	ErrorFormatJSON ErrorFormat = "json"
+	// ErrorFormatProblem renders the errors as RFC 9457 problem details.
+	ErrorFormatProblem ErrorFormat = "problem"
+	// ProblemContentType is the media type of the problem details
+	ProblemContentType = "application/problem+json"
+)
+
+// errorResponseConfig is the configuration of the error responses
+var errorResponseConfig atomic.Pointer[ErrorResponseConfig]
+
+// SetErrorResponseConfig sets the configuration of the error responses written by RespondWithError.
+func SetErrorResponseConfig(config ErrorResponseConfig) {
+	errorResponseConfig.Store(&config)
+}
+
+// getErrorResponseConfig returns the configuration of the error responses
+func getErrorResponseConfig() ErrorResponseConfig {
+	if config := errorResponseConfig.Load(); config != nil {
+		return *config
+	}
+	return ErrorResponseConfig{Format: ErrorFormatJSON}
+}
+
+// problemRequested returns true if the error response is rendered as problem details, as
+// configured or because the client accepts problem details explicitly.
+func problemRequested(ctx *gin.Context, config ErrorResponseConfig) bool {
+	if config.Format == ErrorFormatProblem {
+		return true
+	}
+
+	for _, mediaRange := range strings.Split(ctx.GetHeader("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil || mediaType != ProblemContentType {
+			continue
+		}
+		// a quality of zero, e.g. q=0 or q=0.0, rejects the media type
+		if quality, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(quality, 64); err != nil || parsed <= 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// newProblemDetails returns the problem details of the error response
+func newProblemDetails(ctx *gin.Context, config ErrorResponseConfig, statusCode int, response ErrorResponse) ProblemDetails {
+	problem := ProblemDetails{
+		Type:      "about:blank",
+		Title:     http.StatusText(statusCode),
+		Status:    statusCode,
+		Detail:    response.Error,
+		Instance:  ctx.Request.URL.Path,
+		Code:      response.Code,
+		Fields:    response.Fields,
+		RequestID: GetRequestID(ctx),
+	}
+	if definition, ok := defaultCatalog.Lookup(response.Code); ok && definition.DocURL != "" {
+		problem.Type = definition.DocURL
+	} else if config.ProblemTypeURI != "" && response.Code != 0 {
+		problem.Type = fmt.Sprintf("%s/%d", strings.TrimSuffix(config.ProblemTypeURI, "/"), response.Code)
+	}
+	return problem
+}
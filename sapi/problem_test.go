@@ -0,0 +1,38 @@
+package sapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestProblemRequested(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		config   ErrorResponseConfig
+		expected bool
+	}{
+		{"no accept", "", ErrorResponseConfig{}, false},
+		{"json", "application/json", ErrorResponseConfig{}, false},
+		{"problem", ProblemContentType, ErrorResponseConfig{}, true},
+		{"problem among others", "application/json, " + ProblemContentType + ";q=0.5", ErrorResponseConfig{}, true},
+		{"zero quality", ProblemContentType + ";q=0", ErrorResponseConfig{}, false},
+		{"decimal zero quality", ProblemContentType + ";q=0.0", ErrorResponseConfig{}, false},
+		{"invalid quality", ProblemContentType + ";q=high", ErrorResponseConfig{}, false},
+		{"configured", "application/json", ErrorResponseConfig{Format: ErrorFormatProblem}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/orders", nil)
+			ctx.Request.Header.Set("Accept", test.accept)
+			if requested := problemRequested(ctx, test.config); requested != test.expected {
+				t.Fatalf("expected %t, got %t", test.expected, requested)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package sapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/s3ndd/sen-go/log"
+)
+
+// Recovery returns a middleware recovering the panics of the handlers. The panic is logged with
+// its stack trace and the request data by the request logger, and the request is answered with
+// the standard error response of a PrivateError, unless the response was already written.
+// Panics on connections closed by the client are logged at warn level without a response.
+// Use it after RequestLogger, so the panics are logged with the request fields.
+func Recovery(config RecoveryConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			recoverPanic(ctx, config, recovered)
+		}()
+		ctx.Next()
+	}
+}
+
+// recoverPanic logs the recovered panic, calls the panic hook and responds with the error
+func recoverPanic(ctx *gin.Context, config RecoveryConfig, recovered interface{}) {
+	err, ok := recovered.(error)
+	if ok {
+		err = fmt.Errorf("panic: %w", err)
+	} else {
+		err = fmt.Errorf("panic: %v", recovered)
+	}
+	// the stack trace is captured in the deferred call, so it includes the panicking frames
+	err = log.WithStack(err)
+
+	logger := log.ContextLogger(ctx).WithField("request", extractRequestData(ctx)).WithError(err)
+	if brokenPipe(err) {
+		logger.Warn("Connection closed by the client")
+		ctx.Abort()
+		return
+	}
+	logger.Error("Panic recovered")
+
+	if config.OnPanic != nil {
+		config.OnPanic(ctx, err)
+	}
+
+	if ctx.Writer.Written() {
+		ctx.Abort()
+		return
+	}
+	// the panic is private, the client gets the status text only
+	RespondWithError(ctx, NewPrivateError(errors.New(http.StatusText(http.StatusInternalServerError))))
+}
+
+// brokenPipe returns true if the error is a write to a connection closed by the client
+func brokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
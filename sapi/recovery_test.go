@@ -0,0 +1,131 @@
+package sapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/s3ndd/sen-go/log"
+	"github.com/s3ndd/sen-go/log/logtest"
+)
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		statusCode int
+		body       string
+		level      log.Level
+		message    string
+		onPanic    bool
+	}{
+		{
+			name:       "panic",
+			handler:    func(ctx *gin.Context) { panic("boom") },
+			statusCode: http.StatusInternalServerError,
+			body:       http.StatusText(http.StatusInternalServerError),
+			level:      log.LevelError,
+			message:    "Panic recovered",
+			onPanic:    true,
+		},
+		{
+			name:       "error panic",
+			handler:    func(ctx *gin.Context) { panic(errors.New("secret failure")) },
+			statusCode: http.StatusInternalServerError,
+			body:       http.StatusText(http.StatusInternalServerError),
+			level:      log.LevelError,
+			message:    "Panic recovered",
+			onPanic:    true,
+		},
+		{
+			name: "response written",
+			handler: func(ctx *gin.Context) {
+				ctx.String(http.StatusAccepted, "partial")
+				panic("boom")
+			},
+			statusCode: http.StatusAccepted,
+			body:       "partial",
+			level:      log.LevelError,
+			message:    "Panic recovered",
+			onPanic:    true,
+		},
+		{
+			name:       "broken pipe",
+			handler:    func(ctx *gin.Context) { panic(fmt.Errorf("write: %w", syscall.EPIPE)) },
+			statusCode: http.StatusOK,
+			level:      log.LevelWarn,
+			message:    "Connection closed by the client",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger := logtest.New()
+			var panicErr error
+			router := gin.New()
+			router.Use(
+				RequestLogger(RequestLoggerConfig{Logger: logger}),
+				Recovery(RecoveryConfig{OnPanic: func(ctx *gin.Context, err error) { panicErr = err }}),
+			)
+			router.GET("/orders", test.handler)
+
+			request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			request.Header.Set(log.RequestIDHeaderName, "req-1")
+			response := serve(router, request)
+
+			if response.Code != test.statusCode {
+				t.Fatalf("expected status %d, got %d", test.statusCode, response.Code)
+			}
+			if !strings.Contains(response.Body.String(), test.body) || strings.Contains(response.Body.String(), "secret") {
+				t.Fatalf("unexpected body %s", response.Body.String())
+			}
+			if test.statusCode == http.StatusInternalServerError {
+				body := ErrorResponse{}
+				if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil || body.Error != test.body {
+					t.Fatalf("expected an error response, got %s", response.Body.String())
+				}
+			}
+
+			logger.RequireLogged(t, test.level, test.message, log.String(log.RequestIDKey, "req-1"))
+			entry := logger.FilterMessage(test.message)[0]
+			if _, ok := entry.Field("request"); !ok {
+				t.Fatalf("expected the request data, got %v", entry.FieldMap())
+			}
+			recorded, _ := entry.Field("error")
+			err, _ := recorded.(error)
+			var stack interface{}
+			for _, field := range log.ErrorFields("error", err) {
+				if field.Key == "error_stack" {
+					stack = field.Value
+				}
+			}
+			if !strings.Contains(fmt.Sprint(stack), "TestRecovery") {
+				t.Fatalf("expected the stack trace of the panic, got %v", stack)
+			}
+			if (panicErr != nil) != test.onPanic {
+				t.Fatalf("expected OnPanic to be called: %t, got %v", test.onPanic, panicErr)
+			}
+		})
+	}
+}
+
+func TestRecoveryRepanicsAbortHandler(t *testing.T) {
+	router := gin.New()
+	router.Use(Recovery(RecoveryConfig{}))
+	router.GET("/orders", func(ctx *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Fatalf("expected http.ErrAbortHandler to be panicked again, got %v", recovered)
+		}
+	}()
+	serve(router, httptest.NewRequest(http.MethodGet, "/orders", nil))
+}
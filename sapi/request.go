@@ -1,22 +1,23 @@
 package sapi
 
 import (
-	"fmt"
-	"github.com/s3ndd/sen-go/log"
-	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/s3ndd/sen-go/log"
 )
 
 // defaultPageSize is the default page size for paginated requests
 const defaultPageSize = 100
 
-// RequestBody binds the request body to the given struct
+// RequestBody binds the request body to the given struct.
+// It returns a ValidationError with the invalid fields keyed by JSON path and status 422 if
+// the body failed validation, or status 400 if the body is malformed.
 func RequestBody(ctx *gin.Context, request interface{}) error {
 	if err := ctx.ShouldBindJSON(request); err != nil {
-		log.Global().WithField("req", request).Error(fmt.Sprintf("failed request, %s", err))
-		return NewValidationError(err.Error(), http.StatusBadRequest)
+		// the body may carry personal data or credentials, so only the error is logged
+		log.ContextLogger(ctx.Request.Context()).WithError(err).Warn("Failed to bind the request body")
+		return bindingError(err, request)
 	}
 	return nil
 }
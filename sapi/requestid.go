@@ -0,0 +1,43 @@
+package sapi
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/s3ndd/sen-go/log"
+)
+
+// RequestID returns a middleware assigning a request id to each request. The request id is taken
+// from the X-Request-Id header if valid, generated otherwise, returned in the response header and
+// stored in the gin and request contexts, so the request loggers, the audit records and the
+// downstream requests of the client package carry it.
+func RequestID(config RequestIDConfig) gin.HandlerFunc {
+	generate := config.Generator
+	if generate == nil {
+		generate = log.NewRequestID
+	}
+
+	return func(ctx *gin.Context) {
+		assignRequestID(ctx, generate)
+		ctx.Next()
+	}
+}
+
+// GetRequestID returns the request id of the request, or an empty string if none was assigned.
+func GetRequestID(ctx *gin.Context) string {
+	return log.RequestIDFromContext(ctx)
+}
+
+// assignRequestID assigns a request id to the request unless the RequestID middleware already did
+func assignRequestID(ctx *gin.Context, generate func() string) string {
+	if requestID := ctx.GetString(log.RequestIDKey); requestID != "" {
+		return requestID
+	}
+
+	requestID := ctx.GetHeader(log.RequestIDHeaderName)
+	if !log.ValidRequestID(requestID) {
+		requestID = generate()
+	}
+	ctx.Set(log.RequestIDKey, requestID)
+	ctx.Request = ctx.Request.WithContext(log.ContextWithRequestID(ctx.Request.Context(), requestID))
+	ctx.Header(log.RequestIDHeaderName, requestID)
+	return requestID
+}
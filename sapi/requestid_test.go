@@ -0,0 +1,77 @@
+package sapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/s3ndd/sen-go/log"
+	"github.com/s3ndd/sen-go/log/logtest"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{"from the header", "req-1", "req-1"},
+		{"generated", "", "generated"},
+		{"invalid header", "bad id\n", "generated"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var fromGin, fromRequest string
+			router := gin.New()
+			router.Use(RequestID(RequestIDConfig{Generator: func() string { return "generated" }}))
+			router.GET("/orders", func(ctx *gin.Context) {
+				fromGin = GetRequestID(ctx)
+				fromRequest = log.RequestIDFromContext(ctx.Request.Context())
+				ctx.Status(http.StatusOK)
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if test.header != "" {
+				request.Header.Set(log.RequestIDHeaderName, test.header)
+			}
+			response := serve(router, request)
+
+			if fromGin != test.expected || fromRequest != test.expected {
+				t.Fatalf("expected the request id %q, got %q and %q", test.expected, fromGin, fromRequest)
+			}
+			if header := response.Header().Get(log.RequestIDHeaderName); header != test.expected {
+				t.Fatalf("expected the response header %q, got %q", test.expected, header)
+			}
+		})
+	}
+}
+
+func TestRequestIDBeforeRequestLogger(t *testing.T) {
+	logger := logtest.New()
+	router := gin.New()
+	router.Use(
+		RequestID(RequestIDConfig{Generator: func() string { return "first" }}),
+		RequestLogger(RequestLoggerConfig{Logger: logger}),
+	)
+	router.GET("/orders", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	response := serve(router, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	// the request logger keeps the request id assigned by the RequestID middleware
+	logger.RequireLogged(t, log.LevelInfo, "HTTP request", log.String(log.RequestIDKey, "first"))
+	if header := response.Header().Get(log.RequestIDHeaderName); header != "first" {
+		t.Fatalf("unexpected response header %q", header)
+	}
+}
+
+func TestGetRequestIDWithoutMiddleware(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/orders", nil)
+	if requestID := GetRequestID(ctx); requestID != "" {
+		t.Fatalf("expected no request id, got %q", requestID)
+	}
+}
@@ -20,16 +20,17 @@ func RespondWithStatusCode(ctx *gin.Context, statusCode int) {
 // RespondWithErrorAndLog responds with the given error and logs it
 func RespondWithErrorAndLog(ctx *gin.Context, respErr error) {
 	if statusCodeErr, ok := respErr.(StatusCode); ok && statusCodeErr.StatusCode() >= http.StatusInternalServerError {
-		log.ContextLogger(ctx).WithFields(log.Fields{
-			"request": extractRequestData(ctx),
-			"error":   respErr.Error(),
-		}).Error("Internal Server Error")
+		log.ContextLogger(ctx).WithField("request", extractRequestData(ctx)).
+			WithError(respErr).
+			Error("Internal Server Error")
 	}
 
 	RespondWithError(ctx, respErr)
 }
 
-// RespondWithError responds with the given error
+// RespondWithError responds with the given error, as an ErrorResponse or as problem details
+// depending on the ErrorResponseConfig and the Accept header, translated to the locale of the
+// Accept-Language header if the default translator has message bundles
 func RespondWithError(ctx *gin.Context, respErr error) {
 	statusCode := http.StatusInternalServerError
 	if statusCodeErr, ok := respErr.(StatusCode); ok {
@@ -49,13 +50,20 @@ func RespondWithError(ctx *gin.Context, respErr error) {
 	}
 
 	response.Error = respErr.Error()
+	localizeErrorResponse(ctx, respErr, &response)
+
+	if config := getErrorResponseConfig(); problemRequested(ctx, config) {
+		ctx.Header("Content-Type", ProblemContentType)
+		ctx.AbortWithStatusJSON(statusCode, newProblemDetails(ctx, config, statusCode, response))
+		return
+	}
 	ctx.AbortWithStatusJSON(statusCode, response)
 }
 
 // extractRequestData extracts the request data from the gin context
 func extractRequestData(ctx *gin.Context) *RequestForLog {
 	return &RequestForLog{
-		RequestID: ctx.GetString(log.RequestIDHeaderName),
+		RequestID: GetRequestID(ctx),
 		Method:    ctx.Request.Method,
 		URL:       ctx.Request.URL.String(),
 		Host:      ctx.Request.Host,
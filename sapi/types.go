@@ -1,5 +1,13 @@
 package sapi
 
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/s3ndd/sen-go/log"
+)
+
 // StatusCode is an interface for status code
 type StatusCode interface {
 	StatusCode() int
@@ -18,11 +26,28 @@ type ErrorFields interface {
 	ErrorFields() ErrorField
 }
 
+// MessageParams is an interface for the parameters of the localized error message
+type MessageParams interface {
+	MessageParams() map[string]string
+}
+
+// FieldMessages is an interface for the localizable messages of the error fields
+type FieldMessages interface {
+	FieldMessages() map[string]FieldMessage
+}
+
+// FieldMessage is the translation key and the parameters of the message of an error field
+type FieldMessage struct {
+	Key    string
+	Params map[string]string
+}
+
 // APIResponseError is an error for API response
 type APIResponseError struct {
 	statusCode int
 	errorCode  int
 	err        error
+	params     map[string]string
 }
 
 // PrivateError is an error for private
@@ -31,14 +56,17 @@ type PrivateError struct {
 	message    string
 	errorCode  int
 	err        error
+	params     map[string]string
 }
 
 // ValidationError is an error for validation
 type ValidationError struct {
-	statusCode int
-	errorCode  int
-	message    string
-	fields     ErrorField
+	statusCode    int
+	errorCode     int
+	message       string
+	fields        ErrorField
+	params        map[string]string
+	fieldMessages map[string]FieldMessage
 }
 
 // ErrorResponse is a response for error
@@ -56,3 +84,94 @@ type RequestForLog struct {
 	Host      string
 	ClientIP  string
 }
+
+// LogLevelRequest is a request to change the log level
+type LogLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+	TTL   string `json:"ttl"`
+}
+
+// LogLevelResponse is a response for the log level
+type LogLevelResponse struct {
+	Level      string     `json:"level"`
+	Configured string     `json:"configured"`
+	RevertAt   *time.Time `json:"revert_at,omitempty"`
+}
+
+// RequestLoggerConfig is the configuration for the request logging middleware
+type RequestLoggerConfig struct {
+	// Logger is the base logger of the requests, the logger of the request context by default.
+	Logger log.Logger
+	// SkipPaths are the request paths without an access log line, e.g. /healthz.
+	SkipPaths []string
+	// SkipRoutes are the route templates without an access log line, e.g. /users/:id.
+	SkipRoutes []string
+	// Skip returns true for the requests without an access log line.
+	Skip func(ctx *gin.Context) bool
+}
+
+// RequestIDConfig is the configuration for the request id middleware
+type RequestIDConfig struct {
+	// Generator generates the request ids, log.NewRequestID (UUIDv7) by default, e.g. log.NewULID.
+	Generator func() string
+}
+
+// RecoveryConfig is the configuration for the panic recovery middleware
+type RecoveryConfig struct {
+	// OnPanic is called with the recovered panic, as an error with its stack trace, before the
+	// response is written, e.g. to alert.
+	OnPanic func(ctx *gin.Context, err error)
+}
+
+// ErrorResponseConfig is the configuration of the error responses
+type ErrorResponseConfig struct {
+	// Format is the format of the error responses, ErrorFormatJSON by default. Problem details
+	// are also rendered to the clients accepting application/problem+json explicitly.
+	Format ErrorFormat
+	// ProblemTypeURI is the base URI of the problem types, suffixed with the error code, e.g.
+	// https://api.example.com/problems. The documentation URL of the error catalog takes
+	// precedence, and the type is about:blank when empty or without error code.
+	ProblemTypeURI string
+}
+
+// ProblemDetails is an RFC 9457 problem details response, extended with the error code,
+// the validation fields and the request id
+type ProblemDetails struct {
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Status    int        `json:"status"`
+	Detail    string     `json:"detail,omitempty"`
+	Instance  string     `json:"instance,omitempty"`
+	Code      int        `json:"code,omitempty"`
+	Fields    ErrorField `json:"fields,omitempty"`
+	RequestID string     `json:"request_id,omitempty"`
+}
+
+// ErrorDefinition is an error code registered in an ErrorCatalog
+type ErrorDefinition struct {
+	// Code is the error code, unique in the catalog.
+	Code int `json:"code"`
+	// Name is the identifier of the error, e.g. user_not_found.
+	Name string `json:"name"`
+	// Status is the HTTP status of the error, 4xx or 5xx.
+	Status int `json:"status"`
+	// Message is the message of the error responses, the status text by default.
+	Message string `json:"message"`
+	// DocURL is the documentation of the error, also the type of its problem details.
+	DocURL string `json:"doc_url,omitempty"`
+	// Retryable is true if the request may succeed when retried.
+	Retryable bool `json:"retryable"`
+}
+
+// ErrorCatalog is a registry of error codes
+type ErrorCatalog struct {
+	mu          sync.RWMutex
+	definitions map[int]ErrorDefinition
+}
+
+// Translator translates the error messages with the message bundles of the locales
+type Translator struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	bundles       map[string]map[string]string
+}
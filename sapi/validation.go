@@ -0,0 +1,197 @@
+package sapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// validationMessages are the messages of the validation tags, by tag
+var validationMessages = struct {
+	sync.RWMutex
+	messages map[string]string
+}{messages: map[string]string{
+	"required":      "{field} is required",
+	"required_if":   "{field} is required",
+	"required_with": "{field} is required",
+	"email":         "{field} must be a valid email address",
+	"url":           "{field} must be a valid URL",
+	"uri":           "{field} must be a valid URI",
+	"uuid":          "{field} must be a valid UUID",
+	"datetime":      "{field} must be a date time in the {param} layout",
+	"min":           "{field} must be at least {param}",
+	"max":           "{field} must be at most {param}",
+	"len":           "{field} must have a length of {param}",
+	"gt":            "{field} must be greater than {param}",
+	"gte":           "{field} must be greater than or equal to {param}",
+	"lt":            "{field} must be less than {param}",
+	"lte":           "{field} must be less than or equal to {param}",
+	"eq":            "{field} must be equal to {param}",
+	"ne":            "{field} must not be equal to {param}",
+	"eqfield":       "{field} must be equal to {param}",
+	"nefield":       "{field} must not be equal to {param}",
+	"oneof":         "{field} must be one of {param}",
+	"numeric":       "{field} must be numeric",
+	"alpha":         "{field} must contain letters only",
+	"alphanum":      "{field} must contain letters and digits only",
+	"unique":        "{field} must contain unique values",
+}}
+
+// RegisterValidation registers a custom validation tag on the validator of gin, with the message
+// of the invalid fields, e.g. "{field} must be a valid SKU". The message may refer to the field
+// path as {field} and to the tag parameter as {param}, and is translated with the key validation.<tag>.
+func RegisterValidation(tag string, fn validator.Func, message string) error {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("the gin validator is not a go-playground validator")
+	}
+	if err := validate.RegisterValidation(tag, fn); err != nil {
+		return fmt.Errorf("failed to register the %s validation, %w", tag, err)
+	}
+	RegisterValidationMessage(tag, message)
+	return nil
+}
+
+// RegisterValidationMessage sets the message of the invalid fields of a validation tag
+func RegisterValidationMessage(tag, message string) {
+	validationMessages.Lock()
+	defer validationMessages.Unlock()
+
+	validationMessages.messages[tag] = message
+}
+
+// bindingError returns the validation error of a request body that could not be bound to the
+// request: 422 with the invalid fields if the body failed validation, 400 if it is malformed
+func bindingError(err error, request interface{}) ValidationError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		validationErr := NewValidationError("the request body is invalid", http.StatusUnprocessableEntity)
+		for _, fieldErr := range validationErrs {
+			path := fieldPath(reflect.TypeOf(request), fieldErr.StructNamespace())
+			params := map[string]string{"field": path, "param": fieldErr.Param(), "tag": fieldErr.Tag()}
+			validationErr = validationErr.WithFieldMessage(path, validationMessage(fieldErr.Tag(), params), "validation."+fieldErr.Tag(), params)
+		}
+		return validationErr
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return NewValidationError("the request body is empty", http.StatusBadRequest)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return NewValidationError("the request body is truncated JSON", http.StatusBadRequest)
+	case errors.As(err, &syntaxErr):
+		return NewValidationError(fmt.Sprintf("the request body is malformed JSON at offset %d", syntaxErr.Offset), http.StatusBadRequest)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		params := map[string]string{"field": typeErr.Field, "param": typeErr.Type.String(), "tag": "type"}
+		return NewValidationError("the request body has invalid types", http.StatusBadRequest).
+			WithFieldMessage(typeErr.Field, fmt.Sprintf("%s must be a %s", typeErr.Field, jsonTypeName(typeErr.Type)), "validation.type", params)
+	}
+	return NewValidationError("the request body is malformed: "+err.Error(), http.StatusBadRequest)
+}
+
+// validationMessage returns the message of an invalid field
+func validationMessage(tag string, params map[string]string) string {
+	validationMessages.RLock()
+	message, ok := validationMessages.messages[tag]
+	validationMessages.RUnlock()
+	if !ok {
+		message = "{field} failed the {tag} validation"
+	}
+
+	replacements := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(replacements...).Replace(message)
+}
+
+// fieldPath returns the JSON path in the request body of the field of the request type, e.g.
+// items[0].name for the CreateOrder.Items[0].Name namespace. The fields of embedded structs
+// are promoted as by encoding/json.
+func fieldPath(t reflect.Type, namespace string) string {
+	_, namespace, _ = strings.Cut(namespace, ".")
+
+	var path []string
+	for _, segment := range strings.Split(namespace, ".") {
+		name, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		t = indirectType(t)
+		if t == nil || t.Kind() != reflect.Struct {
+			path = append(path, segment)
+			t = nil
+			continue
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			path = append(path, segment)
+			t = nil
+			continue
+		}
+		t = field.Type
+		for i := strings.Count(index, "["); i > 0 && t != nil; i-- {
+			if t = indirectType(t); t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+				t = t.Elem()
+			}
+		}
+
+		jsonName := jsonFieldName(field)
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		path = append(path, jsonName+index)
+	}
+	return strings.Join(path, ".")
+}
+
+// indirectType returns the type pointed to by pointer types
+func indirectType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonFieldName returns the JSON name of a struct field, or its Go name without json tag
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// jsonTypeName returns the JSON type expected for a Go type
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
@@ -0,0 +1,139 @@
+package sapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+type testAudit struct {
+	CreatedBy string `json:"created_by" binding:"required"`
+}
+
+type testOrderItem struct {
+	SKU      string `json:"sku" binding:"required"`
+	Quantity int    `json:"quantity" binding:"gte=1"`
+}
+
+type testOrder struct {
+	testAudit
+	Customer string           `json:"customer" binding:"required,email"`
+	Items    []*testOrderItem `json:"items" binding:"required,dive"`
+	Note     string           `binding:"max=5"`
+}
+
+// newTestContext returns a gin context of a POST request with the body.
+func newTestContext(body string) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	return ctx
+}
+
+func TestRequestBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		fields     ErrorField
+	}{
+		{
+			name: "valid",
+			body: `{"created_by":"a","customer":"a@example.com","items":[{"sku":"x","quantity":1}]}`,
+		},
+		{
+			name:       "empty",
+			body:       "",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed",
+			body:       `{"customer":`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "syntax error",
+			body:       `{"customer" "a"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid type",
+			body:       `{"created_by":"a","customer":5}`,
+			statusCode: http.StatusBadRequest,
+			fields:     ErrorField{"customer": "customer must be a string"},
+		},
+		{
+			name:       "invalid fields",
+			body:       `{"customer":"nope","items":[{"sku":"x","quantity":1},{"quantity":0}],"Note":"too long"}`,
+			statusCode: http.StatusUnprocessableEntity,
+			fields: ErrorField{
+				"created_by":        "created_by is required",
+				"customer":          "customer must be a valid email address",
+				"items[1].sku":      "items[1].sku is required",
+				"items[1].quantity": "items[1].quantity must be greater than or equal to 1",
+				"Note":              "Note must be at most 5",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := RequestBody(newTestContext(test.body), &testOrder{})
+			if test.statusCode == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+
+			var validationErr ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a ValidationError, got %v", err)
+			}
+			if validationErr.StatusCode() != test.statusCode {
+				t.Fatalf("expected status %d, got %d: %s", test.statusCode, validationErr.StatusCode(), validationErr.Error())
+			}
+			if test.fields != nil && !reflect.DeepEqual(validationErr.ErrorFields(), test.fields) {
+				t.Fatalf("unexpected fields %v", validationErr.ErrorFields())
+			}
+		})
+	}
+}
+
+func TestRequestBodyKeepsTheGinValidator(t *testing.T) {
+	validate := binding.Validator.Engine().(*validator.Validate)
+	err := validate.Struct(testOrderItem{})
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) || validationErrs[0].Namespace() != "testOrderItem.SKU" {
+		t.Fatalf("expected the gin validator to name the fields after the Go fields, got %v", err)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	type request struct {
+		Code string `json:"code" binding:"test_sku"`
+	}
+	err := RegisterValidation("test_sku", func(field validator.FieldLevel) bool {
+		return strings.HasPrefix(field.Field().String(), "SKU-")
+	}, "{field} must be a valid SKU")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = RequestBody(newTestContext(`{"code":"nope"}`), &request{})
+	var validationErr ValidationError
+	if !errors.As(err, &validationErr) || validationErr.ErrorFields()["code"] != "code must be a valid SKU" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if message := validationErr.FieldMessages()["code"]; message.Key != "validation.test_sku" {
+		t.Fatalf("unexpected field message %+v", message)
+	}
+}